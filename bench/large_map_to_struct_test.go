@@ -0,0 +1,46 @@
+package bench
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+	"github.com/mitchellh/mapstructure"
+)
+
+type fewFieldsStruct struct {
+	A int `decodini:"0" mapstructure:"0"`
+	B int `decodini:"1" mapstructure:"1"`
+	C int `decodini:"2" mapstructure:"2"`
+}
+
+func generateLargeStringMap() map[string]int {
+	m := make(map[string]int)
+	for i := range largeMapSize() {
+		m[strconv.Itoa(i)] = i
+	}
+	return m
+}
+
+func BenchmarkLargeMap_to_Struct(b *testing.B) {
+	m := generateLargeStringMap()
+	size := float64(len(m))
+
+	b.Run("Decodini", func(b *testing.B) {
+		for b.Loop() {
+			b.ReportMetric(size, "len/op")
+
+			var res fewFieldsStruct
+			_ = decodini.TransmuteInto(nil, m, &res)
+		}
+	})
+
+	b.Run("Mapstructure", func(b *testing.B) {
+		for b.Loop() {
+			b.ReportMetric(size, "len/op")
+
+			var res fewFieldsStruct
+			_ = mapstructure.Decode(m, &res)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+)
+
+func BenchmarkTransmuter_Struct_to_Struct(b *testing.B) {
+	type (
+		user struct {
+			Name  string
+			Age   int
+			Tags  []string
+			Admin bool
+		}
+		userDTO struct {
+			Name  string
+			Age   int64
+			Tags  []string
+			Admin bool
+		}
+	)
+
+	from := user{Name: "alice", Age: 30, Tags: []string{"a", "b", "c"}, Admin: true}
+
+	b.Run("Transmute", func(b *testing.B) {
+		for b.Loop() {
+			_, _ = decodini.Transmute[userDTO](nil, from)
+		}
+	})
+
+	b.Run("Transmuter", func(b *testing.B) {
+		tm, err := decodini.NewTransmuter[user, userDTO](nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			_, _ = tm.Run(from)
+		}
+	})
+}
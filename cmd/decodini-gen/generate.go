@@ -0,0 +1,1204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+)
+
+const decodiniPath = "github.com/lukasl-dev/decodini/pkg/decodini"
+
+// Names of the helpers that generated functions may depend on.
+const (
+	helperDecode    = "decodiniDecode"
+	helperUnmatched = "decodiniUnmatched"
+	helperUnknown   = "decodiniUnknown"
+)
+
+var (
+	anyType      = types.Universe.Lookup("any").Type()
+	stringAnyMap = types.NewMap(types.Typ[types.String], anyType)
+)
+
+// dynamicTypes are the types of values stored in interfaces that are handled
+// by generated code. Values of other types are decoded reflectively.
+var dynamicTypes = []types.Type{
+	types.Typ[types.Bool],
+	types.Typ[types.String],
+	types.Typ[types.Int],
+	types.Typ[types.Int8],
+	types.Typ[types.Int16],
+	types.Typ[types.Int32],
+	types.Typ[types.Int64],
+	types.Typ[types.Uint],
+	types.Typ[types.Uint8],
+	types.Typ[types.Uint16],
+	types.Typ[types.Uint32],
+	types.Typ[types.Uint64],
+	types.Typ[types.Float32],
+	types.Typ[types.Float64],
+	stringAnyMap,
+	types.NewSlice(anyType),
+}
+
+// genFunc is a generated function.
+type genFunc struct {
+	name string
+	src  bytes.Buffer
+
+	// calls holds the generated functions called by this function, and uses
+	// the import paths and helpers it depends on.
+	calls []*genFunc
+	uses  map[string]bool
+
+	err error
+}
+
+type generator struct {
+	pkg  *types.Package
+	opts options
+
+	funcs   map[string]*genFunc
+	names   map[string]bool
+	imports map[string]string
+
+	// cur is the function that is currently being generated.
+	cur *genFunc
+}
+
+// generate returns the formatted source of the conversions declared by
+// directives for the types of pkg.
+func generate(pkg *types.Package, opts options, directives []directive) ([]byte, error) {
+	g := &generator{
+		pkg:   pkg,
+		opts:  opts,
+		funcs: make(map[string]*genFunc),
+		names: make(map[string]bool),
+		imports: map[string]string{
+			decodiniPath:    "decodini",
+			"fmt":           "fmt",
+			"math":          "math",
+			"slices":        "slices",
+			"unicode/utf16": "utf16",
+		},
+	}
+
+	var roots []*genFunc
+	for _, d := range directives {
+		root, err := g.root(d)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, root)
+	}
+
+	funcs, uses, err := reachable(roots)
+	if err != nil {
+		return nil, err
+	}
+	return g.file(funcs, uses)
+}
+
+// root generates the entry point of the directive d.
+func (g *generator) root(d directive) (*genFunc, error) {
+	to, err := g.lookup(d.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		from        types.Type
+		param, desc string
+	)
+	name := d.funcName()
+	if d.From == "" {
+		from = stringAnyMap
+		param = "m"
+		desc = fmt.Sprintf("decodes m into %s", d.To)
+	} else {
+		if from, err = g.lookup(d.From); err != nil {
+			return nil, err
+		}
+		param = "from"
+		desc = fmt.Sprintf("converts from into %s", d.To)
+	}
+	if g.names[name] {
+		return nil, fmt.Errorf("duplicate directive for %s", name)
+	}
+	g.names[name] = true
+
+	fn := &genFunc{name: name, uses: map[string]bool{}}
+	g.cur = fn
+	conv, err := g.conv(nil, from, to, false)
+	g.cur = nil
+	if err != nil {
+		if d.From == "" {
+			return nil, fmt.Errorf("decode %s: %w", d.To, err)
+		}
+		return nil, fmt.Errorf("transmute %s %s: %w", d.From, d.To, err)
+	}
+	g.cur = fn
+	toType, fromType := g.typeString(to), g.typeString(from)
+	g.cur = nil
+	fmt.Fprintf(&fn.src, "// %s %s.\n", name, desc)
+	fmt.Fprintf(&fn.src, "func %s(%s %s) (%s, error) {\n", name, param, fromType, toType)
+	fmt.Fprintf(&fn.src, "var to %s\n", toType)
+	fmt.Fprintf(&fn.src, "err := %s(&to, %s)\n", conv.name, param)
+	fmt.Fprintf(&fn.src, "return to, err\n}\n")
+	return fn, nil
+}
+
+// lookup returns the type declared as name in the package scope.
+func (g *generator) lookup(name string) (types.Type, error) {
+	obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not declared in package %s", name, g.pkg.Name())
+	}
+	return obj.Type(), nil
+}
+
+// conv returns the function converting values of type from into values of
+// type to, generating it if necessary. If inline is true, to is an embedded
+// struct decoded from the same source as its parent.
+func (g *generator) conv(path decodini.Path, from, to types.Type, inline bool) (*genFunc, error) {
+	// The inline flag only affects the check for unknown fields.
+	inline = inline && g.opts.strict
+
+	key := typeKey(from) + " -> " + typeKey(to)
+	if inline {
+		key += " (inline)"
+	}
+	if fn, ok := g.funcs[key]; ok {
+		if fn.err != nil {
+			return nil, fn.err
+		}
+		g.cur.calls = append(g.cur.calls, fn)
+		return fn, nil
+	}
+
+	name := "convert" + typeName(g.pkg, from) + "To" + typeName(g.pkg, to)
+	if inline {
+		name += "Inline"
+	}
+	for i, base := 2, name; g.names[name]; i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+	g.names[name] = true
+
+	fn := &genFunc{name: name, uses: map[string]bool{}}
+	g.funcs[key] = fn
+
+	parent := g.cur
+	g.cur = fn
+	fmt.Fprintf(&fn.src, "func %s(to *%s, from %s) error {\n", name, g.typeString(to), g.typeString(from))
+	fn.err = g.convBody(path, from, to, inline)
+	fmt.Fprintf(&fn.src, "}\n")
+	g.cur = parent
+
+	if fn.err != nil {
+		return nil, fn.err
+	}
+	parent.calls = append(parent.calls, fn)
+	return fn, nil
+}
+
+// convBody writes the body of the function converting from into to. The
+// source value is named from and the target pointer to.
+func (g *generator) convBody(path decodini.Path, from, to types.Type, inline bool) error {
+	fromKind, toKind := kindOf(from), kindOf(to)
+
+	switch {
+	case isEnumType(from) || isEnumType(to):
+		// Whether enums are registered is only known at run time.
+		g.useHelper(helperDecode)
+		g.printf("return %s(to, from)\n", helperDecode)
+		return nil
+
+	case isEmptyInterface(from):
+		return g.convFromAny(to)
+
+	case fromKind == reflect.Interface:
+		g.useHelper(helperDecode)
+		g.printf("return %s(to, from)\n", helperDecode)
+		return nil
+
+	case toKind == reflect.Pointer:
+		return g.convIntoPointer(from, to)
+
+	case fromKind == reflect.Pointer:
+		src := "from"
+		for ; kindOf(from) == reflect.Pointer; from, _ = deref(from) {
+			g.printf("if %s == nil {\n", src)
+			g.printf("var zero %s\n*to = zero\nreturn nil\n}\n", g.typeString(to))
+			src = "*" + src
+		}
+		return g.assignReturn(path, "*to", src, from, to)
+
+	case hasTuple(g.opts.encodeTag, from) || hasTuple(g.opts.decodeTag, to),
+		hasReflectiveField(g.opts.encodeTag, from),
+		hasReflectiveField(g.opts.decodeTag, to):
+		// Tuples, keyed collections and delimited strings are rare enough to
+		// not be worth generated code.
+		g.useHelper(helperDecode)
+		g.printf("return %s(to, from)\n", helperDecode)
+		return nil
+
+	case toKind == reflect.Interface && !isPrimitive(fromKind):
+		if !types.AssignableTo(from, to) {
+			return planErrorf(
+				decodini.ErrTypeMismatch,
+				path,
+				"cannot decode %s into %s", reflectString(from), reflectString(to),
+			)
+		}
+		g.printf("var v %s\n", g.typeString(from))
+		if err := g.assign(path, "v", "from", from, from, ""); err != nil {
+			return err
+		}
+		g.printf("*to = v\nreturn nil\n")
+		return nil
+
+	case isPrimitive(toKind):
+		if toKind == reflect.String &&
+			(fromKind == reflect.Slice || fromKind == reflect.Array) {
+			if ok := g.convSliceToString(from, to); ok {
+				return nil
+			}
+		}
+		if !isPrimitive(fromKind) {
+			return planErrorf(
+				decodini.ErrTypeMismatch,
+				path,
+				"leaf decoder can't decode non-leaf %s", fromKind,
+			)
+		}
+		if err := g.scalar(path, "*to", "from", from, to, ""); err != nil {
+			return err
+		}
+		g.printf("return nil\n")
+		return nil
+	}
+
+	switch toKind {
+	case reflect.Struct:
+		switch {
+		case fromKind == reflect.Struct:
+			return g.convStructFromStruct(path, from, to, inline)
+		case fromKind == reflect.Map && kindOf(mapOf(from).Key()) == reflect.String:
+			return g.convStructFromMap(path, from, to, inline)
+		case fromKind == reflect.Map:
+			g.useHelper(helperDecode)
+			g.printf("return %s(to, from)\n", helperDecode)
+			return nil
+		}
+		return planErrorf(
+			decodini.ErrTypeMismatch,
+			path,
+			"cannot decode %s into struct", fromKind,
+		)
+
+	case reflect.Slice:
+		switch fromKind {
+		case reflect.String:
+			if ok := g.convStringToSlice(from, to); ok {
+				return nil
+			}
+		case reflect.Slice, reflect.Array:
+			return g.convSliceFromSlice(path, from, to)
+		case reflect.Map:
+			// Whether the keys are used as indices depends on their values.
+			g.useHelper(helperDecode)
+			g.printf("return %s(to, from)\n", helperDecode)
+			return nil
+		}
+		return planErrorf(
+			decodini.ErrTypeMismatch,
+			path,
+			"cannot decode %s into slice", fromKind,
+		)
+
+	case reflect.Array:
+		if fromKind == reflect.String && kindOf(elemOf(to)) == reflect.Uint8 {
+			g.useHelper(helperDecode)
+			g.printf("return %s(to, from)\n", helperDecode)
+			return nil
+		}
+		return planErrorf(
+			decodini.ErrUnsupportedKind,
+			path,
+			"decodini does currently not support arrays",
+		)
+
+	case reflect.Map:
+		switch fromKind {
+		case reflect.Map:
+			return g.convMapFromMap(path, from, to)
+		case reflect.Struct:
+			return g.convMapFromStruct(path, from, to)
+		case reflect.Slice, reflect.Array:
+			return g.convMapFromSlice(path, from, to)
+		}
+		return planErrorf(
+			decodini.ErrTypeMismatch,
+			path,
+			"cannot decode %s into map", fromKind,
+		)
+
+	default:
+		return planErrorf(
+			decodini.ErrUnsupportedKind,
+			path,
+			"cannot decode into %s", reflectString(to),
+		)
+	}
+}
+
+// convFromAny writes a type switch over the dynamic type of from. Dynamic
+// types that are not handled by generated code are decoded reflectively.
+func (g *generator) convFromAny(to types.Type) error {
+	if kindOf(to) == reflect.Pointer {
+		return g.convFromAnyIntoPointer(to)
+	}
+
+	var (
+		cases    bytes.Buffer
+		assigned []string
+	)
+	for _, dyn := range dynamicTypes {
+		if types.AssignableTo(dyn, to) && isPrimitive(kindOf(dyn)) {
+			assigned = append(assigned, g.typeString(dyn))
+			continue
+		}
+
+		body, err := g.capture(func() error {
+			return g.assignReturn(nil, "*to", "from", dyn, to)
+		})
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&cases, "case %s:\n%s", g.typeString(dyn), body)
+	}
+
+	g.useHelper(helperDecode)
+	if len(assigned) == 0 && cases.Len() == 0 {
+		g.printf("if from == nil {\n")
+		g.printf("var zero %s\n*to = zero\nreturn nil\n}\n", g.typeString(to))
+		g.printf("return %s(to, from)\n", helperDecode)
+		return nil
+	}
+
+	g.printf("switch from := from.(type) {\n")
+	g.printf("case nil:\nvar zero %s\n*to = zero\nreturn nil\n", g.typeString(to))
+	if len(assigned) > 0 {
+		g.printf("case %s:\n*to = from\nreturn nil\n", strings.Join(assigned, ", "))
+	}
+	g.printf("%s}\n", cases.String())
+	g.printf("return %s(to, from)\n", helperDecode)
+	return nil
+}
+
+// convFromAnyIntoPointer writes the conversion of from into the pointer type
+// to. Nil interfaces result in a nil target pointer, while other dynamic types
+// handled by generated code are decoded into the allocated element.
+func (g *generator) convFromAnyIntoPointer(to types.Type) error {
+	elem, _ := deref(to)
+	fn, err := g.conv(nil, anyType, elem, false)
+	if err != nil {
+		return err
+	}
+
+	dyn := make([]string, len(dynamicTypes))
+	for i, typ := range dynamicTypes {
+		dyn[i] = g.typeString(typ)
+	}
+
+	g.useHelper(helperDecode)
+	g.printf("switch from.(type) {\n")
+	g.printf("case nil:\n*to = nil\nreturn nil\n")
+	g.printf("case %s:\n", strings.Join(dyn, ", "))
+	g.printf("if *to == nil {\n*to = new(%s)\n}\n", g.typeString(elem))
+	g.printf("return %s(*to, from)\n}\n", fn.name)
+	g.printf("return %s(to, from)\n", helperDecode)
+	return nil
+}
+
+// convIntoPointer writes the conversion into the pointer type to. Nil source
+// pointers result in a nil target pointer.
+func (g *generator) convIntoPointer(from, to types.Type) error {
+	src := "from"
+	for ; kindOf(from) == reflect.Pointer; from, _ = deref(from) {
+		g.printf("if %s == nil {\n*to = nil\nreturn nil\n}\n", src)
+		src = "*" + src
+	}
+
+	elem, _ := deref(to)
+	g.printf("if *to == nil {\n*to = new(%s)\n}\n", g.typeString(elem))
+	return g.assignReturn(nil, "**to", src, from, elem)
+}
+
+func (g *generator) convStructFromStruct(
+	path decodini.Path,
+	from, to types.Type,
+	inline bool,
+) error {
+	srcPlan := planOf(g.opts.encodeTag, from)
+	dstPlan := planOf(g.opts.decodeTag, to)
+
+	for _, field := range dstPlan.fields {
+		fieldPath := appendPath(path, decodini.Field(field.name))
+		dst := field.selector("to")
+
+		i, ok := srcPlan.byName[field.name]
+		if !ok && field.embedded {
+			if err := g.embedded(path, dst, from, field.typ); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok {
+			if g.opts.ignoreUnmatched {
+				continue
+			}
+			err := planErrorf(
+				decodini.ErrUnmatchedField,
+				fieldPath,
+				"struct field %s is unmatched in source type %s", field.name, reflectString(from),
+			)
+			err.Suggestions = decodini.Suggest(
+				field.name,
+				missingNames(srcPlan.names, dstPlan.names),
+			)
+			return err
+		}
+
+		srcField := srcPlan.flat[i]
+		closing := g.guardPointers(srcField, field.name)
+		err := g.assign(
+			fieldPath,
+			dst,
+			srcField.selector("from"),
+			srcField.typ,
+			field.typ,
+			fmt.Sprintf("decodini.Field(%q)", field.name),
+		)
+		if err != nil {
+			return err
+		}
+		g.printf("%s", closing)
+	}
+
+	if g.opts.strict && !inline {
+		for _, field := range srcPlan.flat {
+			if dstPlan.hasName(field.name) {
+				continue
+			}
+			err := planErrorf(
+				decodini.ErrUnknownField,
+				appendPath(path, decodini.Field(field.name)),
+				"source field %s does not match any struct field of %s", field.name, reflectString(to),
+			)
+			err.Suggestions = decodini.Suggest(
+				field.name,
+				missingNames(dstPlan.names, srcPlan.names),
+			)
+			return err
+		}
+	}
+
+	g.printf("return nil\n")
+	return nil
+}
+
+// guardPointers writes the checks for nil embedded pointers on the way to the
+// source field, and returns the code closing them. A nil pointer leaves the
+// field unmatched.
+func (g *generator) guardPointers(field fieldPlan, name string) string {
+	var closing string
+	for i := range len(field.sel) - 1 {
+		if !field.ptr[i] {
+			continue
+		}
+		sel := "from." + strings.Join(field.sel[:i+1], ".")
+		if g.opts.ignoreUnmatched {
+			g.printf("if %s != nil {\n", sel)
+			closing += "}\n"
+			continue
+		}
+		g.use("fmt")
+		g.printf("if %s == nil {\n", sel)
+		g.printf(
+			"return decodini.NewDecodeError(decodini.ErrUnmatchedField, decodini.Path{decodini.Field(%q)}, fmt.Errorf(\"struct field %%s is unmatched in source tree\", %q))\n",
+			name, name,
+		)
+		g.printf("}\n")
+	}
+	return closing
+}
+
+// embedded writes the inline conversion of from into the embedded struct (or
+// struct pointer) field dst of type typ.
+func (g *generator) embedded(path decodini.Path, dst string, from, typ types.Type) error {
+	elem, isPtr := deref(typ)
+	fn, err := g.conv(path, from, elem, true)
+	if err != nil {
+		return err
+	}
+
+	if isPtr {
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", dst, dst, g.typeString(elem))
+		g.printf("if err := %s(%s, from); err != nil {\nreturn err\n}\n", fn.name, dst)
+		return nil
+	}
+	g.printf("if err := %s(&%s, from); err != nil {\nreturn err\n}\n", fn.name, dst)
+	return nil
+}
+
+func (g *generator) convStructFromMap(
+	path decodini.Path,
+	from, to types.Type,
+	inline bool,
+) error {
+	elem := mapOf(from).Elem()
+	dstPlan := planOf(g.opts.decodeTag, to)
+	names := quoteAll(dstPlan.names)
+
+	for _, field := range dstPlan.fields {
+		fieldPath := appendPath(path, decodini.Field(field.name))
+		dst := field.selector("to")
+		seg := fmt.Sprintf("decodini.Key{Value: %q}", field.name)
+
+		g.printf("if v, ok := from[%q]; ok {\n", field.name)
+		if err := g.assign(fieldPath, dst, "v", elem, field.typ, seg); err != nil {
+			return err
+		}
+
+		switch {
+		case field.embedded:
+			g.printf("} else {\n")
+			if err := g.embedded(path, dst, from, field.typ); err != nil {
+				return err
+			}
+		case !g.opts.ignoreUnmatched:
+			g.useHelper(helperUnmatched)
+			g.printf("} else {\n")
+			g.printf("return %s(%q, from, []string{%s})\n", helperUnmatched, field.name, names)
+		}
+		g.printf("}\n")
+	}
+
+	if g.opts.strict && !inline {
+		g.useHelper(helperUnknown)
+		g.printf("for k := range from {\n")
+		if len(names) > 0 {
+			g.printf("switch k {\ncase %s:\ncontinue\n}\n", names)
+		}
+		g.printf("return %s(k, from, []string{%s})\n}\n", helperUnknown, names)
+	}
+
+	g.printf("return nil\n")
+	return nil
+}
+
+func (g *generator) convSliceFromSlice(path decodini.Path, from, to types.Type) error {
+	g.printf("s := make(%s, len(from))\n", g.typeString(to))
+	g.printf("for i, v := range from {\n")
+	err := g.assign(
+		appendPath(path, decodini.Wildcard{}),
+		"s[i]",
+		"v",
+		elemOf(from),
+		elemOf(to),
+		"decodini.Index(i)",
+	)
+	if err != nil {
+		return err
+	}
+	g.printf("}\n*to = s\nreturn nil\n")
+	return nil
+}
+
+func (g *generator) convMapFromMap(path decodini.Path, from, to types.Type) error {
+	src, dst := mapOf(from), mapOf(to)
+	if !types.AssignableTo(src.Key(), dst.Key()) {
+		// Keys are converted depending on their values.
+		g.useHelper(helperDecode)
+		g.printf("return %s(to, from)\n", helperDecode)
+		return nil
+	}
+
+	g.printf("m := make(%s, len(from))\n", g.typeString(to))
+	g.printf("for k, v := range from {\n")
+	g.printf("var e %s\n", g.typeString(dst.Elem()))
+	err := g.assign(
+		appendPath(path, decodini.Wildcard{}),
+		"e",
+		"v",
+		src.Elem(),
+		dst.Elem(),
+		"decodini.Key{Value: k}",
+	)
+	if err != nil {
+		return err
+	}
+	g.printf("m[k] = e\n}\n*to = m\nreturn nil\n")
+	return nil
+}
+
+func (g *generator) convMapFromSlice(path decodini.Path, from, to types.Type) error {
+	key := kindOf(mapOf(to).Key())
+	if !isSetType(to) && !isInt(key) && !isUint(key) && key != reflect.String {
+		return planErrorf(
+			decodini.ErrTypeMismatch,
+			path,
+			"cannot use slice indices as map key of type %s", reflectString(mapOf(to).Key()),
+		)
+	}
+
+	g.useHelper(helperDecode)
+	g.printf("return %s(to, from)\n", helperDecode)
+	return nil
+}
+
+func (g *generator) convMapFromStruct(path decodini.Path, from, to types.Type) error {
+	dst := mapOf(to)
+	if !types.AssignableTo(types.Typ[types.String], dst.Key()) {
+		// Field names are converted into keys depending on their values.
+		g.useHelper(helperDecode)
+		g.printf("return %s(to, from)\n", helperDecode)
+		return nil
+	}
+
+	srcPlan := planOf(g.opts.encodeTag, from)
+	g.printf("m := make(%s, %d)\n", g.typeString(to), len(srcPlan.flat))
+	for _, field := range srcPlan.flat {
+		// Fields behind nil embedded pointers are not part of the source.
+		var closing string
+		for i := range len(field.sel) - 1 {
+			if field.ptr[i] {
+				g.printf("if from.%s != nil {\n", strings.Join(field.sel[:i+1], "."))
+				closing += "}\n"
+			}
+		}
+
+		g.printf("{\nvar e %s\n", g.typeString(dst.Elem()))
+		err := g.assign(
+			appendPath(path, decodini.Field(field.name)),
+			"e",
+			field.selector("from"),
+			field.typ,
+			dst.Elem(),
+			fmt.Sprintf("decodini.Field(%q)", field.name),
+		)
+		if err != nil {
+			return err
+		}
+		g.printf("m[%q] = e\n}\n%s", field.name, closing)
+	}
+	g.printf("*to = m\nreturn nil\n")
+	return nil
+}
+
+// stringElemKind classifies the element kinds of slices that are converted
+// from and to strings: bytes, UTF-16 code units or runes.
+func stringElemKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Uint8, reflect.Int8:
+		return "byte"
+	case reflect.Uint16, reflect.Int16:
+		return "uint16"
+	case reflect.Int, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "rune"
+	default:
+		return ""
+	}
+}
+
+// convStringToSlice writes the conversion of a string into a slice of bytes,
+// UTF-16 code units or runes. It reports false if the element type is none of
+// them.
+func (g *generator) convStringToSlice(from, to types.Type) bool {
+	elem := elemOf(to)
+	switch stringElemKind(kindOf(elem)) {
+	case "byte":
+		g.printf("units := []byte(from)\n")
+	case "uint16":
+		g.use("unicode/utf16")
+		g.printf("units := utf16.Encode([]rune(from))\n")
+	case "rune":
+		g.printf("units := []rune(from)\n")
+	default:
+		return false
+	}
+
+	g.printf("s := make(%s, len(units))\n", g.typeString(to))
+	g.printf("for i, u := range units {\ns[i] = %s\n}\n", g.convert(elem, "u"))
+	g.printf("*to = s\nreturn nil\n")
+	return true
+}
+
+// convSliceToString writes the conversion of a slice of bytes, UTF-16 code
+// units or runes into a string. It reports false if the element type is none
+// of them.
+func (g *generator) convSliceToString(from, to types.Type) bool {
+	unit := stringElemKind(kindOf(elemOf(from)))
+	if unit == "" {
+		return false
+	}
+
+	g.printf("units := make([]%s, len(from))\n", unit)
+	g.printf("for i, u := range from {\nunits[i] = %s(u)\n}\n", unit)
+	if unit == "uint16" {
+		g.use("unicode/utf16")
+		g.printf("*to = %s\nreturn nil\n", g.convert(to, "string(utf16.Decode(units))"))
+		return true
+	}
+	g.printf("*to = %s\nreturn nil\n", g.convert(to, "units"))
+	return true
+}
+
+// assign writes the conversion of the expression src of type from into the
+// addressable expression dst of type to. seg is the code of the path segment
+// addressing src in its parent, or empty if src is the parent itself.
+func (g *generator) assign(path decodini.Path, dst, src string, from, to types.Type, seg string) error {
+	if g.isScalar(from, to) {
+		return g.scalar(path, dst, src, from, to, seg)
+	}
+
+	fn, err := g.conv(path, from, to, false)
+	if err != nil {
+		return err
+	}
+	g.printf("if err := %s(%s, %s); err != nil {\n", fn.name, addr(dst), src)
+	if seg == "" {
+		g.printf("return err\n}\n")
+	} else {
+		g.printf("return decodini.PrefixPath(err, %s)\n}\n", seg)
+	}
+	return nil
+}
+
+// assignReturn is like assign, but returns from the current function
+// afterwards.
+func (g *generator) assignReturn(path decodini.Path, dst, src string, from, to types.Type) error {
+	if !g.isScalar(from, to) {
+		fn, err := g.conv(path, from, to, false)
+		if err != nil {
+			return err
+		}
+		g.printf("return %s(%s, %s)\n", fn.name, addr(dst), src)
+		return nil
+	}
+
+	if err := g.scalar(path, dst, src, from, to, ""); err != nil {
+		return err
+	}
+	g.printf("return nil\n")
+	return nil
+}
+
+// isScalar reports whether the conversion of from into to is a plain
+// assignment or conversion of primitive values.
+func (g *generator) isScalar(from, to types.Type) bool {
+	fromKind, toKind := kindOf(from), kindOf(to)
+	return isPrimitive(fromKind) &&
+		fromKind != reflect.Pointer &&
+		fromKind != reflect.Interface &&
+		isPrimitive(toKind) &&
+		toKind != reflect.Pointer &&
+		!isEnumType(from) &&
+		!isEnumType(to)
+}
+
+// scalar writes the assignment of the primitive src to dst, following the
+// conversion rules of the decodini package.
+func (g *generator) scalar(path decodini.Path, dst, src string, from, to types.Type, seg string) error {
+	fromKind, toKind := kindOf(from), kindOf(to)
+
+	switch {
+	case types.AssignableTo(from, to):
+		g.printf("%s = %s\n", dst, src)
+
+	case isNumeric(fromKind) && isNumeric(toKind):
+		if cond := overflowCond(src, fromKind, toKind); cond != "" {
+			g.use("fmt")
+			g.use("math")
+			at := "nil"
+			if seg != "" {
+				at = "decodini.Path{" + seg + "}"
+			}
+			g.printf("if %s {\n", cond)
+			g.printf(
+				"return decodini.NewDecodeError(decodini.ErrOverflow, %s, fmt.Errorf(\"%%v overflows %%s\", %s, %q))\n",
+				at, src, reflectString(to),
+			)
+			g.printf("}\n")
+		}
+		g.printf("%s = %s\n", dst, g.convert(to, src))
+
+	case fromKind == toKind && types.ConvertibleTo(from, to):
+		g.printf("%s = %s\n", dst, g.convert(to, src))
+
+	default:
+		return planErrorf(
+			decodini.ErrTypeMismatch,
+			path,
+			"cannot decode %s into %s", reflectString(from), reflectString(to),
+		)
+	}
+	return nil
+}
+
+// convert returns the expression converting src into typ.
+func (g *generator) convert(typ types.Type, src string) string {
+	switch types.Unalias(typ).(type) {
+	case *types.Named, *types.Basic:
+		return fmt.Sprintf("%s(%s)", g.typeString(typ), src)
+	default:
+		return fmt.Sprintf("(%s)(%s)", g.typeString(typ), src)
+	}
+}
+
+// capture runs fn and returns what it wrote. Nothing is recorded if fn fails.
+func (g *generator) capture(fn func() error) (string, error) {
+	cur := g.cur
+	tmp := &genFunc{uses: map[string]bool{}}
+	g.cur = tmp
+	err := fn()
+	g.cur = cur
+	if err != nil {
+		return "", err
+	}
+
+	cur.calls = append(cur.calls, tmp.calls...)
+	for use := range tmp.uses {
+		cur.uses[use] = true
+	}
+	return tmp.src.String(), nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.cur.src, format, args...)
+}
+
+// use records that the current function depends on the import path.
+func (g *generator) use(path string) {
+	g.cur.uses[path] = true
+}
+
+// useHelper records that the current function depends on the helper.
+func (g *generator) useHelper(name string) {
+	g.cur.uses[name] = true
+}
+
+// typeString returns the code of typ, qualified with the names of the imports
+// of the generated file.
+func (g *generator) typeString(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		if pkg == g.pkg {
+			return ""
+		}
+		g.use(pkg.Path())
+		if name, ok := g.imports[pkg.Path()]; ok {
+			return name
+		}
+
+		name := pkg.Name()
+		for i := 2; slices.Contains(importNames(g.imports), name); i++ {
+			name = pkg.Name() + strconv.Itoa(i)
+		}
+		g.imports[pkg.Path()] = name
+		return name
+	})
+}
+
+// reachable returns the functions reachable from roots in depth-first order,
+// and the import paths and helpers they depend on.
+func reachable(roots []*genFunc) ([]*genFunc, map[string]bool, error) {
+	var (
+		funcs []*genFunc
+		seen  = map[*genFunc]bool{}
+		uses  = map[string]bool{}
+		visit func(fn *genFunc) error
+	)
+	visit = func(fn *genFunc) error {
+		if seen[fn] {
+			return nil
+		}
+		seen[fn] = true
+		if fn.err != nil {
+			return fn.err
+		}
+
+		funcs = append(funcs, fn)
+		for use := range fn.uses {
+			uses[use] = true
+		}
+		for _, call := range fn.calls {
+			if err := visit(call); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := visit(root); err != nil {
+			return nil, nil, err
+		}
+	}
+	return funcs, uses, nil
+}
+
+// file assembles the generated file.
+func (g *generator) file(funcs []*genFunc, uses map[string]bool) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by decodini-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.Name())
+
+	// The helpers depend on imports themselves.
+	if uses[helperUnmatched] || uses[helperUnknown] {
+		uses["fmt"] = true
+	}
+	if uses[helperUnmatched] {
+		uses["slices"] = true
+	}
+
+	// Standard library imports are grouped before all others.
+	var std, others []string
+	for path := range uses {
+		if _, isImport := g.imports[path]; !isImport {
+			continue
+		}
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	if !slices.Contains(others, decodiniPath) {
+		others = append(others, decodiniPath)
+	}
+	slices.Sort(std)
+	slices.Sort(others)
+
+	buf.WriteString("import (\n")
+	for _, path := range slices.Concat(std, []string{""}, others) {
+		name := g.imports[path]
+		switch {
+		case path == "":
+			buf.WriteString("\n")
+		case name == pathBase(path):
+			fmt.Fprintf(&buf, "%q\n", path)
+		default:
+			fmt.Fprintf(&buf, "%s %q\n", name, path)
+		}
+	}
+	buf.WriteString(")\n\n")
+
+	if uses[helperDecode] {
+		g.writeDecodeHelper(&buf)
+	}
+	for _, fn := range funcs {
+		buf.Write(fn.src.Bytes())
+		buf.WriteString("\n")
+	}
+	if uses[helperUnmatched] {
+		buf.WriteString(unmatchedHelper)
+	}
+	if uses[helperUnknown] {
+		buf.WriteString(unknownHelper)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) writeDecodeHelper(buf *bytes.Buffer) {
+	var decoding []string
+	decoding = append(decoding, fmt.Sprintf("StructTag: %q", g.opts.decodeTag))
+	if g.opts.strict {
+		decoding = append(decoding, "Strict: true")
+	}
+	if g.opts.ignoreUnmatched {
+		decoding = append(decoding, "Unmatched: decodini.DecodeIgnoreUnmatched")
+	}
+
+	fmt.Fprintf(buf, "var (\n")
+	fmt.Fprintf(buf, "decodiniEncoding = decodini.Encoding{StructTag: %q}\n", g.opts.encodeTag)
+	fmt.Fprintf(buf, "decodiniDecoding = decodini.Decoding{%s}\n", strings.Join(decoding, ", "))
+	fmt.Fprintf(buf, ")\n\n")
+	buf.WriteString(decodeHelper)
+}
+
+const decodeHelper = `// decodiniDecode decodes from into to using the reflective decoding. It
+// handles the values whose types are only known at run time.
+func decodiniDecode(to, from any) error {
+	tr := decodini.Encode(&decodiniEncoding, from)
+	return decodini.DecodeInto(&decodiniDecoding, tr, to)
+}
+
+`
+
+const unmatchedHelper = `// decodiniUnmatched returns the error reported for the struct field name that
+// has no counterpart in m. names holds the names of all struct fields.
+func decodiniUnmatched[K ~string, V any](name string, m map[K]V, names []string) error {
+	var candidates []string
+	for k := range m {
+		if !slices.Contains(names, string(k)) {
+			candidates = append(candidates, string(k))
+		}
+	}
+
+	err := decodini.NewDecodeError(
+		decodini.ErrUnmatchedField,
+		decodini.Path{decodini.Key{Value: name}},
+		fmt.Errorf("struct field %s is unmatched in source tree", name),
+	)
+	err.Suggestions = decodini.Suggest(name, candidates)
+	return err
+}
+
+`
+
+const unknownHelper = `// decodiniUnknown returns the error reported for the key of m that does not
+// match any struct field. names holds the names of all struct fields.
+func decodiniUnknown[K ~string, V any](key K, m map[K]V, names []string) error {
+	var candidates []string
+	for _, name := range names {
+		if _, ok := m[K(name)]; !ok {
+			candidates = append(candidates, name)
+		}
+	}
+
+	err := decodini.NewDecodeError(
+		decodini.ErrUnknownField,
+		decodini.Path{decodini.Key{Value: key}},
+		fmt.Errorf("source key %v does not match any struct field", key),
+	)
+	err.Suggestions = decodini.Suggest(string(key), candidates)
+	return err
+}
+
+`
+
+func planErrorf(kind error, path decodini.Path, format string, args ...any) *decodini.PlanError {
+	return &decodini.PlanError{
+		Path: path,
+		Err:  fmt.Errorf(format, args...),
+		Kind: kind,
+	}
+}
+
+// appendPath returns path followed by seg, without writing to the array of
+// path.
+func appendPath(path decodini.Path, seg decodini.PathSegment) decodini.Path {
+	return append(path[:len(path):len(path)], seg)
+}
+
+// reflectString returns the string representation of typ as reported by
+// reflect.Type.String.
+func reflectString(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
+
+// missingNames returns the names that are not contained in exclude.
+func missingNames(names, exclude []string) []string {
+	var missing []string
+	for _, name := range names {
+		if !slices.Contains(exclude, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = strconv.Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func mapOf(typ types.Type) *types.Map {
+	return typ.Underlying().(*types.Map)
+}
+
+// isSetType mirrors isSetType of the decodini package, reporting whether typ
+// is a map type with empty struct or boolean values.
+func isSetType(typ types.Type) bool {
+	m, ok := typ.Underlying().(*types.Map)
+	if !ok {
+		return false
+	}
+	switch elem := m.Elem().Underlying().(type) {
+	case *types.Basic:
+		return elem.Kind() == types.Bool
+	case *types.Struct:
+		return elem.NumFields() == 0
+	default:
+		return false
+	}
+}
+
+// isEnumType reports whether typ may be an enum registered with the decodini
+// package, i.e. a named integer type with a String method.
+func isEnumType(typ types.Type) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return false
+	}
+	basic, ok := named.Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(named, false, named.Obj().Pkg(), "String")
+	_, ok = obj.(*types.Func)
+	return ok
+}
+
+// elemOf returns the element type of the slice or array type typ.
+func elemOf(typ types.Type) types.Type {
+	switch t := typ.Underlying().(type) {
+	case *types.Slice:
+		return t.Elem()
+	case *types.Array:
+		return t.Elem()
+	default:
+		panic("decodini-gen: " + typ.String() + " has no elements")
+	}
+}
+
+func importNames(imports map[string]string) []string {
+	names := make([]string, 0, len(imports))
+	for _, name := range imports {
+		names = append(names, name)
+	}
+	return names
+}
+
+// addr returns the expression taking the address of the addressable
+// expression expr.
+func addr(expr string) string {
+	if deref, ok := strings.CutPrefix(expr, "*"); ok {
+		return deref
+	}
+	return "&" + expr
+}
+
+func pathBase(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+	"github.com/stretchr/testify/assert"
+)
+
+// generateDir runs the generator for the package in dir without writing the
+// output file.
+func generateDir(t *testing.T, dir string) ([]byte, error) {
+	t.Helper()
+
+	pkg, files, err := loadPackage(dir, "decodini_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, directives, err := parseDirectives(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return generate(pkg, opts, directives)
+}
+
+func TestGenerate_UpToDate(t *testing.T) {
+	for _, dir := range []string{"internal/gentest", "internal/gentest/strict"} {
+		t.Run(dir, func(t *testing.T) {
+			a := assert.New(t)
+
+			src, err := generateDir(t, dir)
+			a.NoError(err)
+
+			committed, err := os.ReadFile(filepath.Join(dir, "decodini_gen.go"))
+			a.NoError(err)
+			a.Equal(string(committed), string(src), "run go generate ./...")
+		})
+	}
+}
+
+func TestGenerate_UnmatchedField(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := generateDir(t, "testdata/unmatched")
+	a.ErrorIs(err, decodini.ErrUnmatchedField)
+
+	var planErr *decodini.PlanError
+	if a.True(errors.As(err, &planErr)) {
+		a.Equal(decodini.Path{decodini.Field("Prot")}, planErr.Path)
+		a.Equal([]string{"Port"}, planErr.Suggestions)
+	}
+}
+
+func TestGenerate_Strict_UnknownField(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := generateDir(t, "testdata/unknown")
+	a.ErrorIs(err, decodini.ErrUnknownField)
+
+	var planErr *decodini.PlanError
+	if a.True(errors.As(err, &planErr)) {
+		a.Equal(decodini.Path{decodini.Field("Port")}, planErr.Path)
+	}
+}
+
+func TestLoadPackage_TypeErrors(t *testing.T) {
+	a := assert.New(t)
+
+	_, _, err := loadPackage("testdata/typeerror", "decodini_gen.go")
+	a.ErrorContains(err, "undefined: Port")
+
+	// References to the functions to generate are no errors.
+	_, err = generateDir(t, "testdata/selfref")
+	a.NoError(err)
+}
+
+func TestOptions_Parse(t *testing.T) {
+	a := assert.New(t)
+
+	var opts options
+	a.NoError(opts.parse([]string{"tag=json", "decodetag=yaml", "strict", "unmatched=ignore"}))
+	a.Equal(options{
+		encodeTag:       "json",
+		decodeTag:       "yaml",
+		strict:          true,
+		ignoreUnmatched: true,
+	}, opts)
+
+	a.Error(opts.parse([]string{"unmatched=maybe"}))
+	a.Error(opts.parse([]string{"lenient"}))
+}
+
+func TestOverflowCond(t *testing.T) {
+	a := assert.New(t)
+
+	a.Empty(overflowCond("v", reflect.Int8, reflect.Int64))
+	a.Empty(overflowCond("v", reflect.Uint32, reflect.Int64))
+	a.Empty(overflowCond("v", reflect.Float32, reflect.Float64))
+	a.Equal("v < math.MinInt8 || v > math.MaxInt8", overflowCond("v", reflect.Int, reflect.Int8))
+	a.Equal("v < 0", overflowCond("v", reflect.Int8, reflect.Uint8))
+	a.Equal("uint64(v) > math.MaxInt64", overflowCond("v", reflect.Uint64, reflect.Int64))
+}
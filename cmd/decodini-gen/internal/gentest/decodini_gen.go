@@ -0,0 +1,1214 @@
+// Code generated by decodini-gen. DO NOT EDIT.
+
+package gentest
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"unicode/utf16"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+)
+
+var (
+	decodiniEncoding = decodini.Encoding{StructTag: "decodini"}
+	decodiniDecoding = decodini.Decoding{StructTag: "decodini"}
+)
+
+// decodiniDecode decodes from into to using the reflective decoding. It
+// handles the values whose types are only known at run time.
+func decodiniDecode(to, from any) error {
+	tr := decodini.Encode(&decodiniEncoding, from)
+	return decodini.DecodeInto(&decodiniDecoding, tr, to)
+}
+
+// transmuteTextToText converts from into Text.
+func transmuteTextToText(from Text) (Text, error) {
+	var to Text
+	err := convertTextToText(&to, from)
+	return to, err
+}
+
+func convertTextToText(to *Text, from Text) error {
+	to.S = from.S
+	return nil
+}
+
+// transmuteStructHolderToMapHolder converts from into MapHolder.
+func transmuteStructHolderToMapHolder(from StructHolder) (MapHolder, error) {
+	var to MapHolder
+	err := convertStructHolderToMapHolder(&to, from)
+	return to, err
+}
+
+func convertStructHolderToMapHolder(to *MapHolder, from StructHolder) error {
+	if err := convertShallowFromToMapStringAny(&to.V, from.V); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("V"))
+	}
+	return nil
+}
+
+func convertShallowFromToMapStringAny(to *map[string]any, from ShallowFrom) error {
+	m := make(map[string]any, 2)
+	{
+		var e any
+		e = from.A
+		m["a"] = e
+	}
+	{
+		var e any
+		e = from.B
+		m["B"] = e
+	}
+	*to = m
+	return nil
+}
+
+// transmuteStringsToStrings converts from into Strings.
+func transmuteStringsToStrings(from Strings) (Strings, error) {
+	var to Strings
+	err := convertStringsToStrings(&to, from)
+	return to, err
+}
+
+func convertStringsToStrings(to *Strings, from Strings) error {
+	if err := convertSliceStringToSliceString(&to.V, from.V); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("V"))
+	}
+	return nil
+}
+
+func convertSliceStringToSliceString(to *[]string, from []string) error {
+	s := make([]string, len(from))
+	for i, v := range from {
+		s[i] = v
+	}
+	*to = s
+	return nil
+}
+
+// transmutePtrHolderToPairHolder converts from into PairHolder.
+func transmutePtrHolderToPairHolder(from PtrHolder) (PairHolder, error) {
+	var to PairHolder
+	err := convertPtrHolderToPairHolder(&to, from)
+	return to, err
+}
+
+func convertPtrHolderToPairHolder(to *PairHolder, from PtrHolder) error {
+	if err := convertPtrPairToPair(&to.V, from.V); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("V"))
+	}
+	return nil
+}
+
+func convertPtrPairToPair(to *Pair, from *Pair) error {
+	if from == nil {
+		var zero Pair
+		*to = zero
+		return nil
+	}
+	return convertPairToPair(to, *from)
+}
+
+func convertPairToPair(to *Pair, from Pair) error {
+	to.A = from.A
+	to.B = from.B
+	return nil
+}
+
+// transmuteAnyHolderToPairHolder converts from into PairHolder.
+func transmuteAnyHolderToPairHolder(from AnyHolder) (PairHolder, error) {
+	var to PairHolder
+	err := convertAnyHolderToPairHolder(&to, from)
+	return to, err
+}
+
+func convertAnyHolderToPairHolder(to *PairHolder, from AnyHolder) error {
+	if err := convertAnyToPair(&to.V, from.V); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("V"))
+	}
+	return nil
+}
+
+func convertAnyToPair(to *Pair, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero Pair
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToPair(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToPair(to *Pair, from map[string]any) error {
+	if v, ok := from["A"]; ok {
+		if err := convertAnyToString(&to.A, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "A"})
+		}
+	} else {
+		return decodiniUnmatched("A", from, []string{"A", "B"})
+	}
+	if v, ok := from["B"]; ok {
+		if err := convertAnyToInt(&to.B, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "B"})
+		}
+	} else {
+		return decodiniUnmatched("B", from, []string{"A", "B"})
+	}
+	return nil
+}
+
+func convertAnyToString(to *string, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero string
+		*to = zero
+		return nil
+	case string:
+		*to = from
+		return nil
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertAnyToInt(to *int, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero int
+		*to = zero
+		return nil
+	case int:
+		*to = from
+		return nil
+	case int8:
+		*to = int(from)
+		return nil
+	case int16:
+		*to = int(from)
+		return nil
+	case int32:
+		*to = int(from)
+		return nil
+	case int64:
+		if from < math.MinInt || from > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case uint:
+		if uint64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case uint8:
+		*to = int(from)
+		return nil
+	case uint16:
+		*to = int(from)
+		return nil
+	case uint32:
+		if uint64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case uint64:
+		if uint64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case float32:
+		if math.IsNaN(float64(from)) || float64(from) < math.MinInt64 || float64(from) >= math.MaxInt64 || int64(from) < math.MinInt || int64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case float64:
+		if math.IsNaN(float64(from)) || float64(from) < math.MinInt64 || float64(from) >= math.MaxInt64 || int64(from) < math.MinInt || int64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	}
+	return decodiniDecode(to, from)
+}
+
+// transmuteIntsToIntPtrs converts from into IntPtrs.
+func transmuteIntsToIntPtrs(from Ints) (IntPtrs, error) {
+	var to IntPtrs
+	err := convertIntsToIntPtrs(&to, from)
+	return to, err
+}
+
+func convertIntsToIntPtrs(to *IntPtrs, from Ints) error {
+	if err := convertSliceIntToSlicePtrInt(&to.V, from.V); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("V"))
+	}
+	return nil
+}
+
+func convertSliceIntToSlicePtrInt(to *[]*int, from []int) error {
+	s := make([]*int, len(from))
+	for i, v := range from {
+		if err := convertIntToPtrInt(&s[i], v); err != nil {
+			return decodini.PrefixPath(err, decodini.Index(i))
+		}
+	}
+	*to = s
+	return nil
+}
+
+func convertIntToPtrInt(to **int, from int) error {
+	if *to == nil {
+		*to = new(int)
+	}
+	**to = from
+	return nil
+}
+
+// transmuteIntMapToIntPtrMap converts from into IntPtrMap.
+func transmuteIntMapToIntPtrMap(from IntMap) (IntPtrMap, error) {
+	var to IntPtrMap
+	err := convertIntMapToIntPtrMap(&to, from)
+	return to, err
+}
+
+func convertIntMapToIntPtrMap(to *IntPtrMap, from IntMap) error {
+	if err := convertMapStringIntToMapStringPtrInt(&to.V, from.V); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("V"))
+	}
+	return nil
+}
+
+func convertMapStringIntToMapStringPtrInt(to *map[string]*int, from map[string]int) error {
+	m := make(map[string]*int, len(from))
+	for k, v := range from {
+		var e *int
+		if err := convertIntToPtrInt(&e, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: k})
+		}
+		m[k] = e
+	}
+	*to = m
+	return nil
+}
+
+// transmuteTextsToUnits converts from into Units.
+func transmuteTextsToUnits(from Texts) (Units, error) {
+	var to Units
+	err := convertTextsToUnits(&to, from)
+	return to, err
+}
+
+func convertTextsToUnits(to *Units, from Texts) error {
+	if err := convertStringToSliceByte(&to.Bytes, from.Bytes); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Bytes"))
+	}
+	if err := convertStringToSliceRune(&to.Runes, from.Runes); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Runes"))
+	}
+	if err := convertStringToSliceInt8(&to.Int8s, from.Int8s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int8s"))
+	}
+	if err := convertStringToSliceUint16(&to.Uint16s, from.Uint16s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Uint16s"))
+	}
+	if err := convertStringToSliceInt16(&to.Int16s, from.Int16s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int16s"))
+	}
+	if err := convertStringToSliceInt32(&to.Int32s, from.Int32s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int32s"))
+	}
+	if err := convertStringToSliceInt64(&to.Int64s, from.Int64s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int64s"))
+	}
+	if err := convertStringToSliceUint32(&to.Uint32s, from.Uint32s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Uint32s"))
+	}
+	if err := convertStringToSliceUint64(&to.Uint64s, from.Uint64s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Uint64s"))
+	}
+	return nil
+}
+
+func convertStringToSliceByte(to *[]byte, from string) error {
+	units := []byte(from)
+	s := make([]byte, len(units))
+	for i, u := range units {
+		s[i] = byte(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceRune(to *[]rune, from string) error {
+	units := []rune(from)
+	s := make([]rune, len(units))
+	for i, u := range units {
+		s[i] = rune(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceInt8(to *[]int8, from string) error {
+	units := []byte(from)
+	s := make([]int8, len(units))
+	for i, u := range units {
+		s[i] = int8(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceUint16(to *[]uint16, from string) error {
+	units := utf16.Encode([]rune(from))
+	s := make([]uint16, len(units))
+	for i, u := range units {
+		s[i] = uint16(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceInt16(to *[]int16, from string) error {
+	units := utf16.Encode([]rune(from))
+	s := make([]int16, len(units))
+	for i, u := range units {
+		s[i] = int16(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceInt32(to *[]int32, from string) error {
+	units := []rune(from)
+	s := make([]int32, len(units))
+	for i, u := range units {
+		s[i] = int32(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceInt64(to *[]int64, from string) error {
+	units := []rune(from)
+	s := make([]int64, len(units))
+	for i, u := range units {
+		s[i] = int64(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceUint32(to *[]uint32, from string) error {
+	units := []rune(from)
+	s := make([]uint32, len(units))
+	for i, u := range units {
+		s[i] = uint32(u)
+	}
+	*to = s
+	return nil
+}
+
+func convertStringToSliceUint64(to *[]uint64, from string) error {
+	units := []rune(from)
+	s := make([]uint64, len(units))
+	for i, u := range units {
+		s[i] = uint64(u)
+	}
+	*to = s
+	return nil
+}
+
+// transmuteUnitsToTexts converts from into Texts.
+func transmuteUnitsToTexts(from Units) (Texts, error) {
+	var to Texts
+	err := convertUnitsToTexts(&to, from)
+	return to, err
+}
+
+func convertUnitsToTexts(to *Texts, from Units) error {
+	if err := convertSliceByteToString(&to.Bytes, from.Bytes); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Bytes"))
+	}
+	if err := convertSliceRuneToString(&to.Runes, from.Runes); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Runes"))
+	}
+	if err := convertSliceInt8ToString(&to.Int8s, from.Int8s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int8s"))
+	}
+	if err := convertSliceUint16ToString(&to.Uint16s, from.Uint16s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Uint16s"))
+	}
+	if err := convertSliceInt16ToString(&to.Int16s, from.Int16s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int16s"))
+	}
+	if err := convertSliceInt32ToString(&to.Int32s, from.Int32s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int32s"))
+	}
+	if err := convertSliceInt64ToString(&to.Int64s, from.Int64s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Int64s"))
+	}
+	if err := convertSliceUint32ToString(&to.Uint32s, from.Uint32s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Uint32s"))
+	}
+	if err := convertSliceUint64ToString(&to.Uint64s, from.Uint64s); err != nil {
+		return decodini.PrefixPath(err, decodini.Field("Uint64s"))
+	}
+	return nil
+}
+
+func convertSliceByteToString(to *string, from []byte) error {
+	units := make([]byte, len(from))
+	for i, u := range from {
+		units[i] = byte(u)
+	}
+	*to = string(units)
+	return nil
+}
+
+func convertSliceRuneToString(to *string, from []rune) error {
+	units := make([]rune, len(from))
+	for i, u := range from {
+		units[i] = rune(u)
+	}
+	*to = string(units)
+	return nil
+}
+
+func convertSliceInt8ToString(to *string, from []int8) error {
+	units := make([]byte, len(from))
+	for i, u := range from {
+		units[i] = byte(u)
+	}
+	*to = string(units)
+	return nil
+}
+
+func convertSliceUint16ToString(to *string, from []uint16) error {
+	units := make([]uint16, len(from))
+	for i, u := range from {
+		units[i] = uint16(u)
+	}
+	*to = string(string(utf16.Decode(units)))
+	return nil
+}
+
+func convertSliceInt16ToString(to *string, from []int16) error {
+	units := make([]uint16, len(from))
+	for i, u := range from {
+		units[i] = uint16(u)
+	}
+	*to = string(string(utf16.Decode(units)))
+	return nil
+}
+
+func convertSliceInt32ToString(to *string, from []int32) error {
+	units := make([]rune, len(from))
+	for i, u := range from {
+		units[i] = rune(u)
+	}
+	*to = string(units)
+	return nil
+}
+
+func convertSliceInt64ToString(to *string, from []int64) error {
+	units := make([]rune, len(from))
+	for i, u := range from {
+		units[i] = rune(u)
+	}
+	*to = string(units)
+	return nil
+}
+
+func convertSliceUint32ToString(to *string, from []uint32) error {
+	units := make([]rune, len(from))
+	for i, u := range from {
+		units[i] = rune(u)
+	}
+	*to = string(units)
+	return nil
+}
+
+func convertSliceUint64ToString(to *string, from []uint64) error {
+	units := make([]rune, len(from))
+	for i, u := range from {
+		units[i] = rune(u)
+	}
+	*to = string(units)
+	return nil
+}
+
+// transmuteWideToSmall converts from into Small.
+func transmuteWideToSmall(from Wide) (Small, error) {
+	var to Small
+	err := convertWideToSmall(&to, from)
+	return to, err
+}
+
+func convertWideToSmall(to *Small, from Wide) error {
+	if from.V < math.MinInt8 || from.V > math.MaxInt8 {
+		return decodini.NewDecodeError(decodini.ErrOverflow, decodini.Path{decodini.Field("V")}, fmt.Errorf("%v overflows %s", from.V, "int8"))
+	}
+	to.V = int8(from.V)
+	return nil
+}
+
+// decodeMapTargetFromMap decodes m into MapTarget.
+func decodeMapTargetFromMap(m map[string]any) (MapTarget, error) {
+	var to MapTarget
+	err := convertMapStringAnyToMapTarget(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToMapTarget(to *MapTarget, from map[string]any) error {
+	if v, ok := from["a"]; ok {
+		if err := convertAnyToString(&to.A, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "a"})
+		}
+	} else {
+		return decodiniUnmatched("a", from, []string{"a", "B"})
+	}
+	if v, ok := from["B"]; ok {
+		if err := convertAnyToPtrInt(&to.B, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "B"})
+		}
+	} else {
+		return decodiniUnmatched("B", from, []string{"a", "B"})
+	}
+	return nil
+}
+
+func convertAnyToPtrInt(to **int, from any) error {
+	switch from.(type) {
+	case nil:
+		*to = nil
+		return nil
+	case bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, map[string]any, []any:
+		if *to == nil {
+			*to = new(int)
+		}
+		return convertAnyToInt(*to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+// decodeAnyMapFromMap decodes m into AnyMap.
+func decodeAnyMapFromMap(m map[string]any) (AnyMap, error) {
+	var to AnyMap
+	err := convertMapStringAnyToAnyMap(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToAnyMap(to *AnyMap, from map[string]any) error {
+	if v, ok := from["V"]; ok {
+		if err := convertAnyToMapStringAny(&to.V, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "V"})
+		}
+	} else {
+		return decodiniUnmatched("V", from, []string{"V"})
+	}
+	return nil
+}
+
+func convertAnyToMapStringAny(to *map[string]any, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero map[string]any
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToMapStringAny(to, from)
+	case []any:
+		return convertSliceAnyToMapStringAny(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToMapStringAny(to *map[string]any, from map[string]any) error {
+	m := make(map[string]any, len(from))
+	for k, v := range from {
+		var e any
+		if err := convertAnyToAny(&e, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: k})
+		}
+		m[k] = e
+	}
+	*to = m
+	return nil
+}
+
+func convertAnyToAny(to *any, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero any
+		*to = zero
+		return nil
+	case bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		*to = from
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToAny(to, from)
+	case []any:
+		return convertSliceAnyToAny(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToAny(to *any, from map[string]any) error {
+	var v map[string]any
+	if err := convertMapStringAnyToMapStringAny(&v, from); err != nil {
+		return err
+	}
+	*to = v
+	return nil
+}
+
+func convertSliceAnyToAny(to *any, from []any) error {
+	var v []any
+	if err := convertSliceAnyToSliceAny(&v, from); err != nil {
+		return err
+	}
+	*to = v
+	return nil
+}
+
+func convertSliceAnyToSliceAny(to *[]any, from []any) error {
+	s := make([]any, len(from))
+	for i, v := range from {
+		if err := convertAnyToAny(&s[i], v); err != nil {
+			return decodini.PrefixPath(err, decodini.Index(i))
+		}
+	}
+	*to = s
+	return nil
+}
+
+func convertSliceAnyToMapStringAny(to *map[string]any, from []any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodeAnySliceFromMap decodes m into AnySlice.
+func decodeAnySliceFromMap(m map[string]any) (AnySlice, error) {
+	var to AnySlice
+	err := convertMapStringAnyToAnySlice(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToAnySlice(to *AnySlice, from map[string]any) error {
+	if v, ok := from["V"]; ok {
+		if err := convertAnyToSliceAny(&to.V, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "V"})
+		}
+	} else {
+		return decodiniUnmatched("V", from, []string{"V"})
+	}
+	return nil
+}
+
+func convertAnyToSliceAny(to *[]any, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero []any
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToSliceAny(to, from)
+	case []any:
+		return convertSliceAnyToSliceAny(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToSliceAny(to *[]any, from map[string]any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodePairHolderFromMap decodes m into PairHolder.
+func decodePairHolderFromMap(m map[string]any) (PairHolder, error) {
+	var to PairHolder
+	err := convertMapStringAnyToPairHolder(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToPairHolder(to *PairHolder, from map[string]any) error {
+	if v, ok := from["V"]; ok {
+		if err := convertAnyToPair(&to.V, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "V"})
+		}
+	} else {
+		return decodiniUnmatched("V", from, []string{"V"})
+	}
+	return nil
+}
+
+// decodeOuterFromMap decodes m into Outer.
+func decodeOuterFromMap(m map[string]any) (Outer, error) {
+	var to Outer
+	err := convertMapStringAnyToOuter(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToOuter(to *Outer, from map[string]any) error {
+	if v, ok := from["Embedded"]; ok {
+		if err := convertAnyToEmbedded(&to.Embedded, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "Embedded"})
+		}
+	} else {
+		if err := convertMapStringAnyToEmbedded(&to.Embedded, from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertAnyToEmbedded(to *Embedded, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero Embedded
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToEmbedded(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToEmbedded(to *Embedded, from map[string]any) error {
+	if v, ok := from["a"]; ok {
+		if err := convertAnyToString(&to.A, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "a"})
+		}
+	} else {
+		return decodiniUnmatched("a", from, []string{"a", "B"})
+	}
+	if v, ok := from["B"]; ok {
+		if err := convertAnyToInt(&to.B, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "B"})
+		}
+	} else {
+		return decodiniUnmatched("B", from, []string{"a", "B"})
+	}
+	return nil
+}
+
+// decodeTwoOuterFromMap decodes m into TwoOuter.
+func decodeTwoOuterFromMap(m map[string]any) (TwoOuter, error) {
+	var to TwoOuter
+	err := convertMapStringAnyToTwoOuter(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToTwoOuter(to *TwoOuter, from map[string]any) error {
+	if v, ok := from["EmbeddedA"]; ok {
+		if err := convertAnyToEmbeddedA(&to.EmbeddedA, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "EmbeddedA"})
+		}
+	} else {
+		if err := convertMapStringAnyToEmbeddedA(&to.EmbeddedA, from); err != nil {
+			return err
+		}
+	}
+	if v, ok := from["EmbeddedB"]; ok {
+		if err := convertAnyToEmbeddedB(&to.EmbeddedB, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "EmbeddedB"})
+		}
+	} else {
+		if err := convertMapStringAnyToEmbeddedB(&to.EmbeddedB, from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertAnyToEmbeddedA(to *EmbeddedA, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero EmbeddedA
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToEmbeddedA(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToEmbeddedA(to *EmbeddedA, from map[string]any) error {
+	if v, ok := from["a"]; ok {
+		if err := convertAnyToString(&to.A, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "a"})
+		}
+	} else {
+		return decodiniUnmatched("a", from, []string{"a"})
+	}
+	return nil
+}
+
+func convertAnyToEmbeddedB(to *EmbeddedB, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero EmbeddedB
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToEmbeddedB(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToEmbeddedB(to *EmbeddedB, from map[string]any) error {
+	if v, ok := from["b"]; ok {
+		if err := convertAnyToInt(&to.B, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "b"})
+		}
+	} else {
+		return decodiniUnmatched("b", from, []string{"b"})
+	}
+	return nil
+}
+
+// decodePtrTargetFromMap decodes m into PtrTarget.
+func decodePtrTargetFromMap(m map[string]any) (PtrTarget, error) {
+	var to PtrTarget
+	err := convertMapStringAnyToPtrTarget(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToPtrTarget(to *PtrTarget, from map[string]any) error {
+	if v, ok := from["v"]; ok {
+		if err := convertAnyToPtrInt(&to.V, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "v"})
+		}
+	} else {
+		return decodiniUnmatched("v", from, []string{"v"})
+	}
+	return nil
+}
+
+// decodePtrPtrTargetFromMap decodes m into PtrPtrTarget.
+func decodePtrPtrTargetFromMap(m map[string]any) (PtrPtrTarget, error) {
+	var to PtrPtrTarget
+	err := convertMapStringAnyToPtrPtrTarget(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToPtrPtrTarget(to *PtrPtrTarget, from map[string]any) error {
+	if v, ok := from["v"]; ok {
+		if err := convertAnyToPtrPtrInt(&to.V, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "v"})
+		}
+	} else {
+		return decodiniUnmatched("v", from, []string{"v"})
+	}
+	return nil
+}
+
+func convertAnyToPtrPtrInt(to ***int, from any) error {
+	switch from.(type) {
+	case nil:
+		*to = nil
+		return nil
+	case bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, map[string]any, []any:
+		if *to == nil {
+			*to = new(*int)
+		}
+		return convertAnyToPtrInt(*to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+// decodeIntPtrMapFromMap decodes m into IntPtrMap.
+func decodeIntPtrMapFromMap(m map[string]any) (IntPtrMap, error) {
+	var to IntPtrMap
+	err := convertMapStringAnyToIntPtrMap(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToIntPtrMap(to *IntPtrMap, from map[string]any) error {
+	if v, ok := from["V"]; ok {
+		if err := convertAnyToMapStringPtrInt(&to.V, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "V"})
+		}
+	} else {
+		return decodiniUnmatched("V", from, []string{"V"})
+	}
+	return nil
+}
+
+func convertAnyToMapStringPtrInt(to *map[string]*int, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero map[string]*int
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToMapStringPtrInt(to, from)
+	case []any:
+		return convertSliceAnyToMapStringPtrInt(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToMapStringPtrInt(to *map[string]*int, from map[string]any) error {
+	m := make(map[string]*int, len(from))
+	for k, v := range from {
+		var e *int
+		if err := convertAnyToPtrInt(&e, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: k})
+		}
+		m[k] = e
+	}
+	*to = m
+	return nil
+}
+
+func convertSliceAnyToMapStringPtrInt(to *map[string]*int, from []any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodeSmallFromMap decodes m into Small.
+func decodeSmallFromMap(m map[string]any) (Small, error) {
+	var to Small
+	err := convertMapStringAnyToSmall(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToSmall(to *Small, from map[string]any) error {
+	if v, ok := from["V"]; ok {
+		if err := convertAnyToInt8(&to.V, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "V"})
+		}
+	} else {
+		return decodiniUnmatched("V", from, []string{"V"})
+	}
+	return nil
+}
+
+func convertAnyToInt8(to *int8, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero int8
+		*to = zero
+		return nil
+	case int8:
+		*to = from
+		return nil
+	case int:
+		if from < math.MinInt8 || from > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case int16:
+		if from < math.MinInt8 || from > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case int32:
+		if from < math.MinInt8 || from > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case int64:
+		if from < math.MinInt8 || from > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case uint:
+		if uint64(from) > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case uint8:
+		if uint64(from) > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case uint16:
+		if uint64(from) > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case uint32:
+		if uint64(from) > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case uint64:
+		if uint64(from) > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case float32:
+		if math.IsNaN(float64(from)) || float64(from) < math.MinInt64 || float64(from) >= math.MaxInt64 || int64(from) < math.MinInt8 || int64(from) > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	case float64:
+		if math.IsNaN(float64(from)) || float64(from) < math.MinInt64 || float64(from) >= math.MaxInt64 || int64(from) < math.MinInt8 || int64(from) > math.MaxInt8 {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int8"))
+		}
+		*to = int8(from)
+		return nil
+	}
+	return decodiniDecode(to, from)
+}
+
+// decodeNestedFromMap decodes m into Nested.
+func decodeNestedFromMap(m map[string]any) (Nested, error) {
+	var to Nested
+	err := convertMapStringAnyToNested(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToNested(to *Nested, from map[string]any) error {
+	if v, ok := from["Items"]; ok {
+		if err := convertAnyToSliceMapTarget(&to.Items, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "Items"})
+		}
+	} else {
+		return decodiniUnmatched("Items", from, []string{"Items"})
+	}
+	return nil
+}
+
+func convertAnyToSliceMapTarget(to *[]MapTarget, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero []MapTarget
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToSliceMapTarget(to, from)
+	case []any:
+		return convertSliceAnyToSliceMapTarget(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToSliceMapTarget(to *[]MapTarget, from map[string]any) error {
+	return decodiniDecode(to, from)
+}
+
+func convertSliceAnyToSliceMapTarget(to *[]MapTarget, from []any) error {
+	s := make([]MapTarget, len(from))
+	for i, v := range from {
+		if err := convertAnyToMapTarget(&s[i], v); err != nil {
+			return decodini.PrefixPath(err, decodini.Index(i))
+		}
+	}
+	*to = s
+	return nil
+}
+
+func convertAnyToMapTarget(to *MapTarget, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero MapTarget
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToMapTarget(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+// decodeTupleHolderFromMap decodes m into TupleHolder.
+func decodeTupleHolderFromMap(m map[string]any) (TupleHolder, error) {
+	var to TupleHolder
+	err := convertMapStringAnyToTupleHolder(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToTupleHolder(to *TupleHolder, from map[string]any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodeKeyedHolderFromMap decodes m into KeyedHolder.
+func decodeKeyedHolderFromMap(m map[string]any) (KeyedHolder, error) {
+	var to KeyedHolder
+	err := convertMapStringAnyToKeyedHolder(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToKeyedHolder(to *KeyedHolder, from map[string]any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodeDelimitedFromMap decodes m into Delimited.
+func decodeDelimitedFromMap(m map[string]any) (Delimited, error) {
+	var to Delimited
+	err := convertMapStringAnyToDelimited(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToDelimited(to *Delimited, from map[string]any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodeDigestFromMap decodes m into Digest.
+func decodeDigestFromMap(m map[string]any) (Digest, error) {
+	var to Digest
+	err := convertMapStringAnyToDigest(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToDigest(to *Digest, from map[string]any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodeLoggerFromMap decodes m into Logger.
+func decodeLoggerFromMap(m map[string]any) (Logger, error) {
+	var to Logger
+	err := convertMapStringAnyToLogger(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToLogger(to *Logger, from map[string]any) error {
+	if v, ok := from["level"]; ok {
+		if err := convertAnyToLevel(&to.Level, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "level"})
+		}
+	} else {
+		return decodiniUnmatched("level", from, []string{"level"})
+	}
+	return nil
+}
+
+func convertAnyToLevel(to *Level, from any) error {
+	return decodiniDecode(to, from)
+}
+
+// decodiniUnmatched returns the error reported for the struct field name that
+// has no counterpart in m. names holds the names of all struct fields.
+func decodiniUnmatched[K ~string, V any](name string, m map[K]V, names []string) error {
+	var candidates []string
+	for k := range m {
+		if !slices.Contains(names, string(k)) {
+			candidates = append(candidates, string(k))
+		}
+	}
+
+	err := decodini.NewDecodeError(
+		decodini.ErrUnmatchedField,
+		decodini.Path{decodini.Key{Value: name}},
+		fmt.Errorf("struct field %s is unmatched in source tree", name),
+	)
+	err.Suggestions = decodini.Suggest(name, candidates)
+	return err
+}
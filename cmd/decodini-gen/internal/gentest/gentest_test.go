@@ -0,0 +1,427 @@
+package gentest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertDecodes asserts that the generated decoding of from behaves like the
+// reflective decoding, and returns the result of the generated decoding.
+func assertDecodes[T any](
+	t *testing.T,
+	generated func(map[string]any) (T, error),
+	from map[string]any,
+) (T, error) {
+	t.Helper()
+
+	want, wantErr := decodini.Decode[T](nil, decodini.Encode(nil, from))
+	got, gotErr := generated(from)
+	assertSameResult(t, want, wantErr, got, gotErr)
+	return got, gotErr
+}
+
+// assertTransmutes asserts that the generated transmutation of from behaves
+// like the reflective transmutation, and returns the result of the generated
+// transmutation.
+func assertTransmutes[From, To any](
+	t *testing.T,
+	generated func(From) (To, error),
+	from From,
+) (To, error) {
+	t.Helper()
+
+	want, wantErr := decodini.Transmute[To](nil, from)
+	got, gotErr := generated(from)
+	assertSameResult(t, want, wantErr, got, gotErr)
+	return got, gotErr
+}
+
+func assertSameResult[T any](t *testing.T, want T, wantErr error, got T, gotErr error) {
+	t.Helper()
+
+	a := assert.New(t)
+	if wantErr == nil {
+		a.NoError(gotErr)
+		a.Equal(want, got)
+		return
+	}
+
+	var wantDec, gotDec *decodini.DecodeError
+	if a.ErrorAs(wantErr, &wantDec) && a.ErrorAs(gotErr, &gotDec) {
+		a.Equal(wantDec.Kind, gotDec.Kind)
+		a.Equal(wantDec.Path(), gotDec.Path())
+		a.Equal(wantDec.Suggestions, gotDec.Suggestions)
+		a.Equal(wantErr.Error(), gotErr.Error())
+	}
+}
+
+func TestGenerated_String_to_String(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := assertTransmutes(t, transmuteTextToText, Text{S: "decodini"})
+	a.NoError(err)
+	a.Equal(Text{S: "decodini"}, to)
+}
+
+func TestGenerated_ShallowStruct_to_ShallowMap(t *testing.T) {
+	a := assert.New(t)
+
+	from := StructHolder{V: ShallowFrom{A: "foo", B: 42, C: true, d: 420.0}}
+
+	to, err := assertTransmutes(t, transmuteStructHolderToMapHolder, from)
+	a.NoError(err)
+	a.Equal(map[string]any{"a": "foo", "B": 42}, to.V)
+}
+
+func TestGenerated_ShallowMap_to_ShallowStruct(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"a": "foo", "B": 42, "C": true}
+
+	to, err := assertDecodes(t, decodeMapTargetFromMap, from)
+	a.NoError(err)
+	if a.NotNil(to.B) {
+		a.Equal(42, *to.B)
+	}
+	a.Equal("foo", to.A)
+	a.Zero(to.C)
+}
+
+func TestGenerated_ShallowMap_to_ShallowMap(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"V": map[string]any{"a": 12, "B": true, "c": "foo"},
+	}
+
+	to, err := assertDecodes(t, decodeAnyMapFromMap, from)
+	a.NoError(err)
+	a.Equal(from["V"], to.V)
+}
+
+func TestGenerated_NestedMap_to_MapOfInterfaces(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"V": map[string]any{
+			"a": map[string]any{
+				"b": []any{1, "foo", nil, map[string]any{"c": 1.5}},
+			},
+		},
+	}
+
+	to, err := assertDecodes(t, decodeAnyMapFromMap, from)
+	a.NoError(err)
+	a.Equal(from["V"], to.V)
+}
+
+func TestGenerated_ShallowMap_to_ShallowSlice(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"V": map[string]any{"a": 12, "B": true, "c": "foo"},
+	}
+
+	to, err := decodeAnySliceFromMap(from)
+	a.NoError(err)
+	a.ElementsMatch([]any{12, true, "foo"}, to.V)
+}
+
+func TestGenerated_ShallowSlice_to_ShallowSlice(t *testing.T) {
+	a := assert.New(t)
+
+	from := Strings{V: []string{"foo", "bar", "baz"}}
+
+	to, err := assertTransmutes(t, transmuteStringsToStrings, from)
+	a.NoError(err)
+	a.Equal(from, to)
+}
+
+func TestGenerated_FromNilPointerOrInterface_SetsZero(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := assertTransmutes(t, transmutePtrHolderToPairHolder, PtrHolder{})
+	a.NoError(err)
+	a.Equal(PairHolder{}, to)
+
+	to, err = assertTransmutes(
+		t,
+		transmuteAnyHolderToPairHolder,
+		AnyHolder{V: (*Pair)(nil)},
+	)
+	a.NoError(err)
+	a.Equal(PairHolder{}, to)
+
+	to, err = assertDecodes(t, decodePairHolderFromMap, map[string]any{"V": nil})
+	a.NoError(err)
+	a.Equal(PairHolder{}, to)
+}
+
+func TestGenerated_FromPointerOrInterface(t *testing.T) {
+	a := assert.New(t)
+
+	pair := Pair{A: "foo", B: 42}
+
+	to, err := assertTransmutes(t, transmutePtrHolderToPairHolder, PtrHolder{V: &pair})
+	a.NoError(err)
+	a.Equal(PairHolder{V: pair}, to)
+
+	to, err = assertTransmutes(t, transmuteAnyHolderToPairHolder, AnyHolder{V: &pair})
+	a.NoError(err)
+	a.Equal(PairHolder{V: pair}, to)
+}
+
+func TestGenerated_OneEmbeddedStruct(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"a": "foo", "B": 42}
+
+	to, err := assertDecodes(t, decodeOuterFromMap, from)
+	a.NoError(err)
+	a.Equal(Outer{Embedded: Embedded{A: "foo", B: 42}}, to)
+}
+
+func TestGenerated_TwoEmbeddedStructs(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"a": "foo", "b": 7}
+
+	to, err := assertDecodes(t, decodeTwoOuterFromMap, from)
+	a.NoError(err)
+	a.Equal(TwoOuter{EmbeddedA: EmbeddedA{A: "foo"}, EmbeddedB: EmbeddedB{B: 7}}, to)
+}
+
+func TestGenerated_Slice_to_SliceOfPointers(t *testing.T) {
+	a := assert.New(t)
+
+	from := Ints{V: []int{1, 2, 3}}
+
+	to, err := assertTransmutes(t, transmuteIntsToIntPtrs, from)
+	a.NoError(err)
+	if a.Len(to.V, 3) {
+		for i, v := range from.V {
+			a.Equal(v, *to.V[i])
+		}
+	}
+}
+
+func TestGenerated_Map_to_MapOfPointers(t *testing.T) {
+	a := assert.New(t)
+
+	from := IntMap{V: map[string]int{"a": 1, "b": 2}}
+
+	to, err := assertTransmutes(t, transmuteIntMapToIntPtrMap, from)
+	a.NoError(err)
+	if a.Len(to.V, 2) {
+		a.Equal(1, *to.V["a"])
+		a.Equal(2, *to.V["b"])
+	}
+}
+
+func TestGenerated_PointerToPointerStructField(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := assertDecodes(t, decodePtrPtrTargetFromMap, map[string]any{"v": 42})
+	a.NoError(err)
+	if a.NotNil(to.V) && a.NotNil(*to.V) {
+		a.Equal(42, **to.V)
+	}
+}
+
+func TestGenerated_NilIntoPointerStructField_LeavesNil(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := assertDecodes(t, decodePtrTargetFromMap, map[string]any{"v": nil})
+	a.NoError(err)
+	a.Nil(to.V)
+
+	toPtr, err := assertDecodes(t, decodePtrPtrTargetFromMap, map[string]any{"v": nil})
+	a.NoError(err)
+	a.Nil(toPtr.V)
+}
+
+func TestGenerated_MapWithNilEntry_to_MapOfPointers(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"V": map[string]any{"a": nil, "b": 42},
+	}
+
+	to, err := assertDecodes(t, decodeIntPtrMapFromMap, from)
+	a.NoError(err)
+	if a.Len(to.V, 2) {
+		a.Nil(to.V["a"])
+		a.Equal(42, *to.V["b"])
+	}
+}
+
+func TestGenerated_String_to_Slices(t *testing.T) {
+	a := assert.New(t)
+
+	const s = "héllö 🌍"
+	from := Texts{
+		Bytes:   s,
+		Runes:   s,
+		Int8s:   s,
+		Uint16s: s,
+		Int16s:  s,
+		Int32s:  s,
+		Int64s:  s,
+		Uint32s: s,
+		Uint64s: s,
+	}
+
+	to, err := assertTransmutes(t, transmuteTextsToUnits, from)
+	a.NoError(err)
+	a.Equal([]byte(s), to.Bytes)
+	a.Equal([]rune(s), to.Runes)
+
+	back, err := assertTransmutes(t, transmuteUnitsToTexts, to)
+	a.NoError(err)
+	a.Equal(from, back)
+}
+
+func TestGenerated_EmptyString_to_Slices(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := assertTransmutes(t, transmuteTextsToUnits, Texts{})
+	a.NoError(err)
+	a.Equal([]byte{}, to.Bytes)
+
+	back, err := assertTransmutes(t, transmuteUnitsToTexts, Units{})
+	a.NoError(err)
+	a.Equal(Texts{}, back)
+}
+
+func TestGenerated_Overflow(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := assertTransmutes(t, transmuteWideToSmall, Wide{V: 300})
+	a.ErrorIs(err, decodini.ErrOverflow)
+
+	for _, v := range []any{300, -129, uint64(128), 128.0, float32(-129)} {
+		_, err = assertDecodes(t, decodeSmallFromMap, map[string]any{"V": v})
+		a.ErrorIs(err, decodini.ErrOverflow, "%T %v", v, v)
+	}
+
+	for _, v := range []any{127, -128, uint8(127), 127.9, -128.9} {
+		_, err = assertDecodes(t, decodeSmallFromMap, map[string]any{"V": v})
+		a.NoError(err, "%T %v", v, v)
+	}
+}
+
+func TestGenerated_TypeMismatch(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := assertDecodes(t, decodeSmallFromMap, map[string]any{"V": "foo"})
+	a.ErrorIs(err, decodini.ErrTypeMismatch)
+
+	_, err = assertDecodes(t, decodeAnyMapFromMap, map[string]any{"V": 42})
+	a.ErrorIs(err, decodini.ErrTypeMismatch)
+}
+
+func TestGenerated_UnmatchedField(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := assertDecodes(t, decodeMapTargetFromMap, map[string]any{"A": "foo", "B": 1})
+	a.ErrorIs(err, decodini.ErrUnmatchedField)
+
+	var decErr *decodini.DecodeError
+	if a.True(errors.As(err, &decErr)) {
+		a.Equal([]string{"A"}, decErr.Suggestions)
+	}
+}
+
+func TestGenerated_NestedErrorPath(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"Items": []any{
+			map[string]any{"a": "foo", "B": 1},
+			map[string]any{"B": 2},
+		},
+	}
+
+	_, err := assertDecodes(t, decodeNestedFromMap, from)
+	a.ErrorIs(err, decodini.ErrUnmatchedField)
+
+	var decErr *decodini.DecodeError
+	if a.True(errors.As(err, &decErr)) {
+		a.Equal(
+			decodini.Path{decodini.Key{Value: "Items"}, decodini.Index(1), decodini.Key{Value: "a"}},
+			decErr.Path(),
+		)
+	}
+}
+
+func TestGenerated_IndexMap_to_Slice(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"V": map[string]any{"2": "c", "0": "a"},
+	}
+
+	to, err := assertDecodes(t, decodeAnySliceFromMap, from)
+	a.NoError(err)
+	a.Equal([]any{"a", nil, "c"}, to.V)
+}
+
+func TestGenerated_Slice_to_TupleStruct(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"p": []any{1, nil, 2},
+	}
+
+	to, err := assertDecodes(t, decodeTupleHolderFromMap, from)
+	a.NoError(err)
+	a.Equal(TuplePoint{X: 1, Y: 2}, to.P)
+
+	_, err = assertDecodes(t, decodeTupleHolderFromMap, map[string]any{"p": []any{1}})
+	a.ErrorIs(err, decodini.ErrUnmatchedField)
+}
+
+func TestGenerated_Slice_to_MapByKey(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"items": []any{
+			map[string]any{"a": "foo", "B": 1},
+			map[string]any{"a": "bar", "B": 2},
+		},
+	}
+
+	to, err := assertDecodes(t, decodeKeyedHolderFromMap, from)
+	a.NoError(err)
+	a.Len(to.Items, 2)
+	a.Equal(2, *to.Items["bar"].B)
+}
+
+func TestGenerated_Delimited(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := assertDecodes(t, decodeDelimitedFromMap, map[string]any{"ports": "80,443"})
+	a.NoError(err)
+	a.Equal([]int{80, 443}, to.Ports)
+}
+
+func TestGenerated_ByteEncodings(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := assertDecodes(t, decodeDigestFromMap, map[string]any{"sum": "ab", "hash": "cafe"})
+	a.NoError(err)
+	a.Equal(Digest{Sum: [2]byte{'a', 'b'}, Hash: []byte{0xca, 0xfe}}, to)
+}
+
+func TestGenerated_Enum(t *testing.T) {
+	a := assert.New(t)
+
+	decodini.RegisterEnumValues(LevelDebug, LevelInfo)
+
+	to, err := assertDecodes(t, decodeLoggerFromMap, map[string]any{"level": "info"})
+	a.NoError(err)
+	a.Equal(LevelInfo, to.Level)
+}
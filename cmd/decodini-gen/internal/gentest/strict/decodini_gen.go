@@ -0,0 +1,213 @@
+// Code generated by decodini-gen. DO NOT EDIT.
+
+package strict
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+)
+
+var (
+	decodiniEncoding = decodini.Encoding{StructTag: "json"}
+	decodiniDecoding = decodini.Decoding{StructTag: "json", Strict: true, Unmatched: decodini.DecodeIgnoreUnmatched}
+)
+
+// decodiniDecode decodes from into to using the reflective decoding. It
+// handles the values whose types are only known at run time.
+func decodiniDecode(to, from any) error {
+	tr := decodini.Encode(&decodiniEncoding, from)
+	return decodini.DecodeInto(&decodiniDecoding, tr, to)
+}
+
+// decodeConfigFromMap decodes m into Config.
+func decodeConfigFromMap(m map[string]any) (Config, error) {
+	var to Config
+	err := convertMapStringAnyToConfig(&to, m)
+	return to, err
+}
+
+func convertMapStringAnyToConfig(to *Config, from map[string]any) error {
+	if v, ok := from["Base"]; ok {
+		if err := convertAnyToBase(&to.Base, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "Base"})
+		}
+	} else {
+		if err := convertMapStringAnyToBaseInline(&to.Base, from); err != nil {
+			return err
+		}
+	}
+	if v, ok := from["name"]; ok {
+		if err := convertAnyToString(&to.Name, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "name"})
+		}
+	}
+	if v, ok := from["port"]; ok {
+		if err := convertAnyToInt(&to.Port, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "port"})
+		}
+	}
+	for k := range from {
+		switch k {
+		case "Base", "id", "name", "port":
+			continue
+		}
+		return decodiniUnknown(k, from, []string{"Base", "id", "name", "port"})
+	}
+	return nil
+}
+
+func convertAnyToBase(to *Base, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero Base
+		*to = zero
+		return nil
+	case map[string]any:
+		return convertMapStringAnyToBase(to, from)
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToBase(to *Base, from map[string]any) error {
+	if v, ok := from["id"]; ok {
+		if err := convertAnyToString(&to.ID, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "id"})
+		}
+	}
+	for k := range from {
+		switch k {
+		case "id":
+			continue
+		}
+		return decodiniUnknown(k, from, []string{"id"})
+	}
+	return nil
+}
+
+func convertAnyToString(to *string, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero string
+		*to = zero
+		return nil
+	case string:
+		*to = from
+		return nil
+	}
+	return decodiniDecode(to, from)
+}
+
+func convertMapStringAnyToBaseInline(to *Base, from map[string]any) error {
+	if v, ok := from["id"]; ok {
+		if err := convertAnyToString(&to.ID, v); err != nil {
+			return decodini.PrefixPath(err, decodini.Key{Value: "id"})
+		}
+	}
+	return nil
+}
+
+func convertAnyToInt(to *int, from any) error {
+	switch from := from.(type) {
+	case nil:
+		var zero int
+		*to = zero
+		return nil
+	case int:
+		*to = from
+		return nil
+	case int8:
+		*to = int(from)
+		return nil
+	case int16:
+		*to = int(from)
+		return nil
+	case int32:
+		*to = int(from)
+		return nil
+	case int64:
+		if from < math.MinInt || from > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case uint:
+		if uint64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case uint8:
+		*to = int(from)
+		return nil
+	case uint16:
+		*to = int(from)
+		return nil
+	case uint32:
+		if uint64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case uint64:
+		if uint64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case float32:
+		if math.IsNaN(float64(from)) || float64(from) < math.MinInt64 || float64(from) >= math.MaxInt64 || int64(from) < math.MinInt || int64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	case float64:
+		if math.IsNaN(float64(from)) || float64(from) < math.MinInt64 || float64(from) >= math.MaxInt64 || int64(from) < math.MinInt || int64(from) > math.MaxInt {
+			return decodini.NewDecodeError(decodini.ErrOverflow, nil, fmt.Errorf("%v overflows %s", from, "int"))
+		}
+		*to = int(from)
+		return nil
+	}
+	return decodiniDecode(to, from)
+}
+
+// transmuteConfigToConfig converts from into Config.
+func transmuteConfigToConfig(from Config) (Config, error) {
+	var to Config
+	err := convertConfigToConfig(&to, from)
+	return to, err
+}
+
+func convertConfigToConfig(to *Config, from Config) error {
+	if err := convertConfigToBaseInline(&to.Base, from); err != nil {
+		return err
+	}
+	to.Name = from.Name
+	to.Port = from.Port
+	return nil
+}
+
+func convertConfigToBaseInline(to *Base, from Config) error {
+	to.ID = from.Base.ID
+	return nil
+}
+
+// decodiniUnknown returns the error reported for the key of m that does not
+// match any struct field. names holds the names of all struct fields.
+func decodiniUnknown[K ~string, V any](key K, m map[K]V, names []string) error {
+	var candidates []string
+	for _, name := range names {
+		if _, ok := m[K(name)]; !ok {
+			candidates = append(candidates, name)
+		}
+	}
+
+	err := decodini.NewDecodeError(
+		decodini.ErrUnknownField,
+		decodini.Path{decodini.Key{Value: key}},
+		fmt.Errorf("source key %v does not match any struct field", key),
+	)
+	err.Suggestions = decodini.Suggest(string(key), candidates)
+	return err
+}
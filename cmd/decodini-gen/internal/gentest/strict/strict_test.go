@@ -0,0 +1,60 @@
+package strict
+
+import (
+	"testing"
+
+	"github.com/lukasl-dev/decodini/pkg/decodini"
+	"github.com/stretchr/testify/assert"
+)
+
+var transmutation = decodini.Transmutation{
+	Encoding: decodini.Encoding{StructTag: "json"},
+	Decoding: decodini.Decoding{
+		StructTag: "json",
+		Strict:    true,
+		Unmatched: decodini.DecodeIgnoreUnmatched,
+	},
+}
+
+func TestGenerated_Strict_UnknownKey(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"id": "x", "name": "foo", "prot": 80}
+
+	want, wantErr := decodini.Transmute[Config](&transmutation, from)
+	got, gotErr := decodeConfigFromMap(from)
+
+	a.Equal(want, got)
+	a.ErrorIs(gotErr, decodini.ErrUnknownField)
+	a.Equal(wantErr.Error(), gotErr.Error())
+
+	var decErr *decodini.DecodeError
+	if a.ErrorAs(gotErr, &decErr) {
+		a.Equal(decodini.Path{decodini.Key{Value: "prot"}}, decErr.Path())
+		a.Equal([]string{"port"}, decErr.Suggestions)
+	}
+}
+
+func TestGenerated_Strict_IgnoresUnmatched(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"id": "x", "name": "foo"}
+
+	want, wantErr := decodini.Transmute[Config](&transmutation, from)
+	a.NoError(wantErr)
+
+	got, err := decodeConfigFromMap(from)
+	a.NoError(err)
+	a.Equal(want, got)
+	a.Equal(Config{Base: Base{ID: "x"}, Name: "foo"}, got)
+}
+
+func TestGenerated_Strict_Transmute(t *testing.T) {
+	a := assert.New(t)
+
+	from := Config{Base: Base{ID: "x"}, Name: "foo", Port: 80}
+
+	got, err := transmuteConfigToConfig(from)
+	a.NoError(err)
+	a.Equal(from, got)
+}
@@ -0,0 +1,19 @@
+// Package strict holds the types converted by code generated by decodini-gen
+// with the json struct tag, strict decoding and ignored unmatched fields.
+package strict
+
+//go:generate go run ../../..
+
+//decodini:options tag=json strict unmatched=ignore
+//decodini:decode Config
+//decodini:transmute Config Config
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Config struct {
+	Base
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
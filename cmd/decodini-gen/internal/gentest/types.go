@@ -0,0 +1,211 @@
+// Package gentest holds the types converted by code generated by
+// decodini-gen. Its tests verify that the generated code behaves like the
+// reflective decoding.
+package gentest
+
+//go:generate go run ../..
+
+//decodini:transmute Text Text
+//decodini:transmute StructHolder MapHolder
+//decodini:transmute Strings Strings
+//decodini:transmute PtrHolder PairHolder
+//decodini:transmute AnyHolder PairHolder
+//decodini:transmute Ints IntPtrs
+//decodini:transmute IntMap IntPtrMap
+//decodini:transmute Texts Units
+//decodini:transmute Units Texts
+//decodini:transmute Wide Small
+//decodini:decode MapTarget
+//decodini:decode AnyMap
+//decodini:decode AnySlice
+//decodini:decode PairHolder
+//decodini:decode Outer
+//decodini:decode TwoOuter
+//decodini:decode PtrTarget
+//decodini:decode PtrPtrTarget
+//decodini:decode IntPtrMap
+//decodini:decode Small
+//decodini:decode Nested
+//decodini:decode TupleHolder
+//decodini:decode KeyedHolder
+//decodini:decode Delimited
+//decodini:decode Digest
+//decodini:decode Logger
+
+type Text struct {
+	S string
+}
+
+type ShallowFrom struct {
+	A string `decodini:"a"`
+	B int
+	C bool `decodini:"-"`
+	d float64
+}
+
+type StructHolder struct {
+	V ShallowFrom
+}
+
+type MapHolder struct {
+	V map[string]any
+}
+
+type MapTarget struct {
+	A string `decodini:"a"`
+	B *int
+	C int `decodini:"-"`
+	x bool
+}
+
+type AnyMap struct {
+	V map[string]any
+}
+
+type AnySlice struct {
+	V []any
+}
+
+type Strings struct {
+	V []string
+}
+
+type Pair struct {
+	A string
+	B int
+}
+
+type PtrHolder struct {
+	V *Pair
+}
+
+type AnyHolder struct {
+	V any
+}
+
+type PairHolder struct {
+	V Pair
+}
+
+type Embedded struct {
+	A string `decodini:"a"`
+	B int
+	C bool `decodini:"-"`
+}
+
+type Outer struct {
+	Embedded
+}
+
+type EmbeddedA struct {
+	A string `decodini:"a"`
+}
+
+type EmbeddedB struct {
+	B int `decodini:"b"`
+}
+
+type TwoOuter struct {
+	EmbeddedA
+	EmbeddedB
+}
+
+type Ints struct {
+	V []int
+}
+
+type IntPtrs struct {
+	V []*int
+}
+
+type IntMap struct {
+	V map[string]int
+}
+
+type IntPtrMap struct {
+	V map[string]*int
+}
+
+type PtrTarget struct {
+	V *int `decodini:"v"`
+}
+
+type PtrPtrTarget struct {
+	V **int `decodini:"v"`
+}
+
+type Texts struct {
+	Bytes   string
+	Runes   string
+	Int8s   string
+	Uint16s string
+	Int16s  string
+	Int32s  string
+	Int64s  string
+	Uint32s string
+	Uint64s string
+}
+
+type Units struct {
+	Bytes   []byte
+	Runes   []rune
+	Int8s   []int8
+	Uint16s []uint16
+	Int16s  []int16
+	Int32s  []int32
+	Int64s  []int64
+	Uint32s []uint32
+	Uint64s []uint64
+}
+
+type Wide struct {
+	V int64
+}
+
+type Small struct {
+	V int8
+}
+
+type Nested struct {
+	Items []MapTarget
+}
+
+type TuplePoint struct {
+	X int
+	Y int `decodini:",index=2"`
+}
+
+type TupleHolder struct {
+	P TuplePoint `decodini:"p,tuple"`
+}
+
+type KeyedHolder struct {
+	Items map[string]MapTarget `decodini:"items,keyby=a"`
+}
+
+type Delimited struct {
+	Ports []int `decodini:"ports,sep=','"`
+}
+
+type Digest struct {
+	Sum  [2]byte `decodini:"sum"`
+	Hash []byte  `decodini:"hash,bytes=hex"`
+}
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+)
+
+func (l Level) String() string {
+	if l == LevelDebug {
+		return "debug"
+	}
+	return "info"
+}
+
+type Logger struct {
+	Level Level `decodini:"level"`
+}
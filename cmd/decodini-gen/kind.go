@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+var basicKinds = map[types.BasicKind]reflect.Kind{
+	types.Bool:          reflect.Bool,
+	types.Int:           reflect.Int,
+	types.Int8:          reflect.Int8,
+	types.Int16:         reflect.Int16,
+	types.Int32:         reflect.Int32,
+	types.Int64:         reflect.Int64,
+	types.Uint:          reflect.Uint,
+	types.Uint8:         reflect.Uint8,
+	types.Uint16:        reflect.Uint16,
+	types.Uint32:        reflect.Uint32,
+	types.Uint64:        reflect.Uint64,
+	types.Uintptr:       reflect.Uintptr,
+	types.Float32:       reflect.Float32,
+	types.Float64:       reflect.Float64,
+	types.Complex64:     reflect.Complex64,
+	types.Complex128:    reflect.Complex128,
+	types.String:        reflect.String,
+	types.UnsafePointer: reflect.UnsafePointer,
+}
+
+// kindOf returns the reflect.Kind of values of typ.
+func kindOf(typ types.Type) reflect.Kind {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return basicKinds[t.Kind()]
+	case *types.Pointer:
+		return reflect.Pointer
+	case *types.Slice:
+		return reflect.Slice
+	case *types.Array:
+		return reflect.Array
+	case *types.Map:
+		return reflect.Map
+	case *types.Struct:
+		return reflect.Struct
+	case *types.Interface:
+		return reflect.Interface
+	case *types.Chan:
+		return reflect.Chan
+	case *types.Signature:
+		return reflect.Func
+	default:
+		return reflect.Invalid
+	}
+}
+
+// isPrimitive mirrors the notion of primitive values of the decodini package.
+func isPrimitive(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
+		return false
+	default:
+		return true
+	}
+}
+
+func isInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUint(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	return isInt(kind) || isUint(kind) || isFloat(kind)
+}
+
+// isEmptyInterface reports whether typ is an interface without methods.
+func isEmptyInterface(typ types.Type) bool {
+	iface, ok := typ.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+// bits returns the size of the integer kind. Platform-dependent kinds are
+// assumed to be as large as possible if max is true and as small as possible
+// otherwise.
+func bits(kind reflect.Kind, max bool) int {
+	switch kind {
+	case reflect.Int8, reflect.Uint8:
+		return 8
+	case reflect.Int16, reflect.Uint16:
+		return 16
+	case reflect.Int32, reflect.Uint32:
+		return 32
+	case reflect.Int64, reflect.Uint64:
+		return 64
+	default:
+		if max {
+			return 64
+		}
+		return 32
+	}
+}
+
+var (
+	minConsts = map[reflect.Kind]string{
+		reflect.Int:   "math.MinInt",
+		reflect.Int8:  "math.MinInt8",
+		reflect.Int16: "math.MinInt16",
+		reflect.Int32: "math.MinInt32",
+		reflect.Int64: "math.MinInt64",
+	}
+	maxConsts = map[reflect.Kind]string{
+		reflect.Int:     "math.MaxInt",
+		reflect.Int8:    "math.MaxInt8",
+		reflect.Int16:   "math.MaxInt16",
+		reflect.Int32:   "math.MaxInt32",
+		reflect.Int64:   "math.MaxInt64",
+		reflect.Uint:    "math.MaxUint",
+		reflect.Uint8:   "math.MaxUint8",
+		reflect.Uint16:  "math.MaxUint16",
+		reflect.Uint32:  "math.MaxUint32",
+		reflect.Uint64:  "math.MaxUint64",
+		reflect.Uintptr: "uint64(^uintptr(0))",
+	}
+)
+
+// overflowCond returns the condition under which the numeric value src of
+// kind from does not fit into the numeric kind to, following the overflow
+// checks of the decodini package. It returns an empty string if the value
+// always fits.
+func overflowCond(src string, from, to reflect.Kind) string {
+	if from == to {
+		return ""
+	}
+
+	var conds []string
+	switch {
+	case isInt(to) && isInt(from):
+		if bits(from, true) > bits(to, false) {
+			conds = append(conds,
+				fmt.Sprintf("%s < %s", src, minConsts[to]),
+				fmt.Sprintf("%s > %s", src, maxConsts[to]),
+			)
+		}
+
+	case isInt(to) && isUint(from):
+		if bits(from, true) > bits(to, false)-1 {
+			conds = append(conds, fmt.Sprintf("uint64(%s) > %s", src, maxConsts[to]))
+		}
+
+	case isInt(to):
+		conds = append(conds,
+			fmt.Sprintf("math.IsNaN(float64(%s))", src),
+			fmt.Sprintf("float64(%s) < math.MinInt64", src),
+			fmt.Sprintf("float64(%s) >= math.MaxInt64", src),
+		)
+		if to != reflect.Int64 {
+			conds = append(conds,
+				fmt.Sprintf("int64(%s) < %s", src, minConsts[to]),
+				fmt.Sprintf("int64(%s) > %s", src, maxConsts[to]),
+			)
+		}
+
+	case isUint(to) && isInt(from):
+		conds = append(conds, fmt.Sprintf("%s < 0", src))
+		if bits(from, true)-1 > bits(to, false) {
+			conds = append(conds, fmt.Sprintf("uint64(%s) > %s", src, maxConsts[to]))
+		}
+
+	case isUint(to) && isUint(from):
+		if bits(from, true) > bits(to, false) {
+			conds = append(conds, fmt.Sprintf("uint64(%s) > %s", src, maxConsts[to]))
+		}
+
+	case isUint(to):
+		conds = append(conds,
+			fmt.Sprintf("math.IsNaN(float64(%s))", src),
+			fmt.Sprintf("float64(%s) < 0", src),
+			fmt.Sprintf("float64(%s) >= math.MaxUint64", src),
+		)
+		if to != reflect.Uint64 {
+			conds = append(conds, fmt.Sprintf("uint64(%s) > %s", src, maxConsts[to]))
+		}
+
+	case to == reflect.Float32 && from == reflect.Float64:
+		conds = append(conds,
+			fmt.Sprintf("math.Abs(float64(%s)) > math.MaxFloat32", src),
+			fmt.Sprintf("!math.IsInf(float64(%s), 0)", src),
+		)
+		return strings.Join(conds, " && ")
+	}
+	return strings.Join(conds, " || ")
+}
+
+// typeName returns a name for typ that can be used as part of an identifier.
+// Named types declared outside of local are prefixed with their package name.
+func typeName(local *types.Package, typ types.Type) string {
+	switch t := types.Unalias(typ).(type) {
+	case *types.Named:
+		name := capitalize(t.Obj().Name())
+		if pkg := t.Obj().Pkg(); pkg != nil && pkg != local {
+			return capitalize(pkg.Name()) + name
+		}
+		return name
+	case *types.Basic:
+		return capitalize(t.Name())
+	case *types.Pointer:
+		return "Ptr" + typeName(local, t.Elem())
+	case *types.Slice:
+		return "Slice" + typeName(local, t.Elem())
+	case *types.Array:
+		return fmt.Sprintf("Array%d%s", t.Len(), typeName(local, t.Elem()))
+	case *types.Map:
+		return "Map" + typeName(local, t.Key()) + typeName(local, t.Elem())
+	case *types.Interface:
+		if t.Empty() {
+			return "Any"
+		}
+		return "Interface"
+	case *types.Chan:
+		return "Chan" + typeName(local, t.Elem())
+	case *types.Struct:
+		return "Struct"
+	default:
+		return "Value"
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,238 @@
+// Command decodini-gen generates reflection-free conversion functions that
+// follow the semantics of the reflective decodini decoding.
+//
+// It is meant to be used with go:generate. The conversions to generate are
+// declared by directives in the Go sources of the package:
+//
+//	//go:generate go run github.com/lukasl-dev/decodini/cmd/decodini-gen
+//
+//	//decodini:options tag=json strict
+//	//decodini:transmute User UserDTO
+//	//decodini:decode Config
+//
+// A transmute directive generates transmute<From>To<To>(From) (To, error),
+// converting between two struct types. A decode directive generates
+// decode<To>FromMap(map[string]any) (To, error), decoding a generic map into a
+// struct type.
+//
+// The options directive mirrors the options of decodini.Transmutation:
+//
+//	tag=<name>        struct tag used for both source and target types
+//	encodetag=<name>  struct tag used for source types
+//	decodetag=<name>  struct tag used for target types
+//	strict            equivalent of Decoding.Strict
+//	unmatched=ignore  equivalent of Decoding.Unmatched = DecodeIgnoreUnmatched
+//
+// Generated functions report the same errors at the same paths as the
+// reflective decoding. Problems that only depend on the types, such as
+// unmatched struct fields of a source struct, are reported when generating.
+// Values stored in interfaces are converted by generated code if their type is
+// a basic type, map[string]any or []any, and decoded reflectively otherwise.
+// Custom decoders, warnings and resource limits are not supported.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package to generate code for")
+	output := flag.String("output", "decodini_gen.go", "name of the generated file")
+	flag.Parse()
+
+	if err := run(*dir, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "decodini-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output string) error {
+	pkg, files, err := loadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	opts, directives, err := parseDirectives(files)
+	if err != nil {
+		return err
+	}
+	if len(directives) == 0 {
+		return fmt.Errorf("no //decodini: directives found in %s", dir)
+	}
+
+	src, err := generate(pkg, opts, directives)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// loadPackage parses and type-checks the package in dir, excluding the
+// previously generated output file.
+func loadPackage(dir, output string) (*types.Package, []*ast.File, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		if name == output {
+			continue
+		}
+		file, err := parser.ParseFile(
+			fset,
+			filepath.Join(dir, name),
+			nil,
+			parser.ParseComments,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, file)
+	}
+
+	// The package may refer to the functions generated by its directives,
+	// which are missing as long as the output file is excluded. All other
+	// type-check errors are reported.
+	_, directives, err := parseDirectives(files)
+	if err != nil {
+		return nil, nil, err
+	}
+	generated := make(map[string]bool, len(directives))
+	for _, d := range directives {
+		generated["undefined: "+d.funcName()] = true
+	}
+
+	var errs []error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			if typeErr, ok := err.(types.Error); !ok || !generated[typeErr.Msg] {
+				errs = append(errs, err)
+			}
+		},
+	}
+	pkg, _ := conf.Check(bp.Name, fset, files, nil)
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("type-check package in %s: %w", dir, errors.Join(errs...))
+	}
+	return pkg, files, nil
+}
+
+// directive is a single conversion to generate. From is empty for decode
+// directives.
+type directive struct {
+	From, To string
+}
+
+// funcName returns the name of the function generated for d.
+func (d directive) funcName() string {
+	if d.From == "" {
+		return "decode" + capitalize(d.To) + "FromMap"
+	}
+	return "transmute" + capitalize(d.From) + "To" + capitalize(d.To)
+}
+
+const directivePrefix = "//decodini:"
+
+// parseDirectives collects the options and conversions declared in files.
+func parseDirectives(files []*ast.File) (options, []directive, error) {
+	opts := options{encodeTag: defaultTag, decodeTag: defaultTag}
+
+	var directives []directive
+	for _, file := range files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				text, ok := strings.CutPrefix(comment.Text, directivePrefix)
+				if !ok {
+					continue
+				}
+
+				fields := strings.Fields(text)
+				if len(fields) == 0 {
+					return opts, nil, fmt.Errorf("empty directive %q", comment.Text)
+				}
+
+				switch fields[0] {
+				case "options":
+					if err := opts.parse(fields[1:]); err != nil {
+						return opts, nil, err
+					}
+				case "transmute":
+					if len(fields) != 3 {
+						return opts, nil, fmt.Errorf(
+							"directive %q: expected two types", comment.Text,
+						)
+					}
+					directives = append(directives, directive{
+						From: fields[1],
+						To:   fields[2],
+					})
+				case "decode":
+					if len(fields) != 2 {
+						return opts, nil, fmt.Errorf(
+							"directive %q: expected one type", comment.Text,
+						)
+					}
+					directives = append(directives, directive{To: fields[1]})
+				default:
+					return opts, nil, fmt.Errorf("unknown directive %q", comment.Text)
+				}
+			}
+		}
+	}
+	return opts, directives, nil
+}
+
+// defaultTag is the struct tag used by default, like in the decodini package.
+const defaultTag = "decodini"
+
+// options mirrors the options of decodini.Transmutation that are supported by
+// generated code.
+type options struct {
+	encodeTag       string
+	decodeTag       string
+	strict          bool
+	ignoreUnmatched bool
+}
+
+func (o *options) parse(fields []string) error {
+	for _, field := range fields {
+		key, value, _ := strings.Cut(field, "=")
+		if strings.HasSuffix(key, "tag") && value == "" {
+			value = defaultTag
+		}
+
+		switch key {
+		case "tag":
+			o.encodeTag, o.decodeTag = value, value
+		case "encodetag":
+			o.encodeTag = value
+		case "decodetag":
+			o.decodeTag = value
+		case "strict":
+			o.strict = true
+		case "unmatched":
+			if value != "ignore" && value != "error" {
+				return fmt.Errorf("option %q: expected ignore or error", field)
+			}
+			o.ignoreUnmatched = value == "ignore"
+		default:
+			return fmt.Errorf("unknown option %q", field)
+		}
+	}
+	return nil
+}
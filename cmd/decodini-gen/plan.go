@@ -0,0 +1,221 @@
+package main
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// structPlan mirrors the struct plans of the decodini package for go/types
+// struct types.
+type structPlan struct {
+	// fields holds the included fields in declaration order. Embedded structs
+	// are kept as a single field.
+	fields []fieldPlan
+
+	// flat holds the included fields in declaration order, with embedded
+	// structs expanded in place.
+	flat []fieldPlan
+
+	// byName maps the names of flat to their position in flat. If multiple
+	// fields resolve to the same name, the first one wins.
+	byName map[string]int
+
+	// names holds the names of all fields, including the names of embedded
+	// structs and the fields they contribute.
+	names []string
+}
+
+// fieldPlan describes a single struct field.
+type fieldPlan struct {
+	name string
+	typ  types.Type
+
+	// sel holds the Go names of the fields selected to reach this field, and
+	// ptr whether each of them is a pointer.
+	sel []string
+	ptr []bool
+
+	embedded bool
+
+	// tuple is true if the field has the tuple tag option.
+	tuple bool
+
+	// reflective is true if the field has a tag option that is only supported
+	// by reflective decoding, i.e. keyby, entries, sep or bytes.
+	reflective bool
+}
+
+// planOf returns the plan of the struct type typ for the given tag.
+func planOf(tag string, typ types.Type) *structPlan {
+	return newStructPlan(tag, typ, map[string]bool{})
+}
+
+func newStructPlan(tag string, typ types.Type, visiting map[string]bool) *structPlan {
+	key := typeKey(typ)
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	plan := &structPlan{byName: make(map[string]int)}
+	st := typ.Underlying().(*types.Struct)
+	for i := range st.NumFields() {
+		v := st.Field(i)
+		stag := reflect.StructTag(st.Tag(i))
+		if !v.Exported() || stag.Get(tag) == "-" {
+			continue
+		}
+
+		parts := tagParts(stag, tag)
+		name := ""
+		if len(parts) > 0 {
+			name = parts[0]
+		}
+		if name == "" {
+			name = v.Name()
+		}
+
+		embeddedType, isPtr := deref(v.Type())
+		_, isStruct := embeddedType.Underlying().(*types.Struct)
+
+		field := fieldPlan{
+			name:     name,
+			typ:      v.Type(),
+			sel:      []string{v.Name()},
+			ptr:      []bool{isPtr},
+			embedded: v.Embedded() && isStruct && !visiting[typeKey(embeddedType)],
+			tuple:    hasOption(parts, "tuple"),
+			reflective: hasOption(parts, "keyby") ||
+				hasOption(parts, "entries") ||
+				hasOption(parts, "sep") ||
+				hasOption(parts, "bytes"),
+		}
+		plan.fields = append(plan.fields, field)
+		plan.names = append(plan.names, name)
+
+		if !field.embedded {
+			plan.addFlat(field)
+			continue
+		}
+
+		sub := newStructPlan(tag, embeddedType, visiting)
+		plan.names = append(plan.names, sub.names...)
+		for _, f := range sub.flat {
+			f.sel = append([]string{v.Name()}, f.sel...)
+			f.ptr = append([]bool{isPtr}, f.ptr...)
+			plan.addFlat(f)
+		}
+	}
+	return plan
+}
+
+func (p *structPlan) addFlat(field fieldPlan) {
+	if _, exists := p.byName[field.name]; !exists {
+		p.byName[field.name] = len(p.flat)
+	}
+	p.flat = append(p.flat, field)
+}
+
+// hasName reports whether name is the name of any field of the plan.
+func (p *structPlan) hasName(name string) bool {
+	for _, n := range p.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// selector returns the expression selecting the field from base.
+func (f *fieldPlan) selector(base string) string {
+	return base + "." + strings.Join(f.sel, ".")
+}
+
+// tagParts splits the struct tag with the given key at every unquoted comma,
+// i.e. into the name and the options.
+func tagParts(stag reflect.StructTag, key string) []string {
+	raw, ok := stag.Lookup(key)
+	if !ok {
+		return nil
+	}
+
+	var (
+		parts []string
+		start int
+		quote byte
+	)
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			parts = append(parts, raw[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, raw[start:])
+}
+
+// hasOption reports whether the options of the tag parts contain opt.
+func hasOption(parts []string, opt string) bool {
+	for _, part := range parts[min(1, len(parts)):] {
+		key, _, _ := strings.Cut(part, "=")
+		if strings.TrimSpace(key) == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTuple mirrors hasTuple of the decodini package, reporting whether typ is
+// a struct type that implements decodini.Tuple or has fields in tuple mode.
+func hasTuple(tag string, typ types.Type) bool {
+	if _, ok := typ.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	mset := types.NewMethodSet(types.NewPointer(typ))
+	if mset.Lookup(nil, "DecodiniTuple") != nil {
+		return true
+	}
+	for _, field := range planOf(tag, typ).flat {
+		if field.tuple {
+			return true
+		}
+	}
+	return false
+}
+
+// hasReflectiveField reports whether typ is a struct type with fields that
+// have tag options only supported by reflective decoding.
+func hasReflectiveField(tag string, typ types.Type) bool {
+	if _, ok := typ.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	for _, field := range planOf(tag, typ).flat {
+		if field.reflective {
+			return true
+		}
+	}
+	return false
+}
+
+// deref returns the element type of typ if it is a pointer.
+func deref(typ types.Type) (types.Type, bool) {
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		return ptr.Elem(), true
+	}
+	return typ, false
+}
+
+// typeKey returns a string identifying typ, including the full path of the
+// packages of named types.
+func typeKey(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		return pkg.Path()
+	})
+}
@@ -0,0 +1,12 @@
+package selfref
+
+//decodini:decode Config
+
+type Config struct {
+	Name string
+}
+
+// Load refers to the function generated for the directive above.
+func Load(m map[string]any) (Config, error) {
+	return decodeConfigFromMap(m)
+}
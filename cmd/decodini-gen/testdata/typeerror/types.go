@@ -0,0 +1,8 @@
+package typeerror
+
+//decodini:decode Config
+
+type Config struct {
+	Name string
+	Port Port
+}
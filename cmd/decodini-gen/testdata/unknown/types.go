@@ -0,0 +1,13 @@
+package unknown
+
+//decodini:options strict
+//decodini:transmute From To
+
+type From struct {
+	Name string
+	Port int
+}
+
+type To struct {
+	Name string
+}
@@ -0,0 +1,13 @@
+package unmatched
+
+//decodini:transmute From To
+
+type From struct {
+	Name string
+	Port int
+}
+
+type To struct {
+	Name string
+	Prot int
+}
@@ -0,0 +1,243 @@
+package decodini
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// ByteEncoding determines how byte slices and arrays are represented as
+// strings. It can be overridden per struct field by the bytes tag option,
+// e.g. `decodini:"id,bytes=hex"`.
+type ByteEncoding int
+
+const (
+	// BytesRaw represents bytes as the string of the same bytes.
+	BytesRaw ByteEncoding = iota
+
+	// BytesBase64 represents bytes in padded standard base64, see RFC 4648.
+	BytesBase64
+
+	// BytesBase64URL represents bytes in padded URL-safe base64, see RFC 4648.
+	BytesBase64URL
+
+	// BytesHex represents bytes in lowercase hexadecimal. Uppercase digits are
+	// accepted when decoding.
+	BytesHex
+)
+
+var byteEncodingNames = map[string]ByteEncoding{
+	"raw":       BytesRaw,
+	"base64":    BytesBase64,
+	"base64url": BytesBase64URL,
+	"hex":       BytesHex,
+}
+
+// String returns the name of the encoding as used by the bytes tag option.
+func (e ByteEncoding) String() string {
+	switch e {
+	case BytesRaw:
+		return "raw"
+	case BytesBase64:
+		return "base64"
+	case BytesBase64URL:
+		return "base64url"
+	case BytesHex:
+		return "hex"
+	default:
+		return fmt.Sprintf("ByteEncoding(%d)", int(e))
+	}
+}
+
+func (e ByteEncoding) valid() bool {
+	return e >= BytesRaw && e <= BytesHex
+}
+
+// encode returns the string representation of b.
+func (e ByteEncoding) encode(b []byte) string {
+	switch e {
+	case BytesBase64:
+		return base64.StdEncoding.EncodeToString(b)
+	case BytesBase64URL:
+		return base64.URLEncoding.EncodeToString(b)
+	case BytesHex:
+		return hex.EncodeToString(b)
+	default:
+		return string(b)
+	}
+}
+
+// decode returns the bytes represented by s. The error of an invalid s holds
+// the offset of the first invalid character.
+func (e ByteEncoding) decode(s string) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch e {
+	case BytesBase64:
+		b, err = base64.StdEncoding.DecodeString(s)
+	case BytesBase64URL:
+		b, err = base64.URLEncoding.DecodeString(s)
+	case BytesHex:
+		b, err = hex.DecodeString(s)
+	default:
+		return []byte(s), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"invalid %s at offset %d: %w", e, invalidOffset(s, err), err,
+		)
+	}
+	return b, nil
+}
+
+// invalidOffset returns the offset of the first invalid character of the
+// base64 or hex string s that caused err. It is the length of s for hex
+// strings of odd length.
+func invalidOffset(s string, err error) int {
+	if corrupt, ok := err.(base64.CorruptInputError); ok {
+		return int(corrupt)
+	}
+	for i := range len(s) {
+		if !isHexDigit(s[i]) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// isByteSequence reports whether typ is a slice or array of bytes.
+func isByteSequence(typ reflect.Type) bool {
+	return (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) &&
+		typ.Elem().Kind() == reflect.Uint8
+}
+
+// bytesOf returns the bytes of the byte slice or array val, whose elements
+// may be of a named byte type.
+func bytesOf(val reflect.Value) []byte {
+	if val.Kind() == reflect.Slice && val.Type().Elem() == byteType {
+		return val.Bytes()
+	}
+	b := make([]byte, val.Len())
+	for i := range b {
+		b[i] = byte(val.Index(i).Uint())
+	}
+	return b
+}
+
+// setBytes sets the elements of the byte slice or array dst, whose elements
+// may be of a named byte type, to b.
+func setBytes(dst reflect.Value, b []byte) {
+	for i, c := range b {
+		dst.Index(i).SetUint(uint64(c))
+	}
+}
+
+var byteType = reflect.TypeFor[byte]()
+
+// hasByteEncodings reports whether typ is a struct type with fields that have
+// the bytes option in the given tag.
+func hasByteEncodings(tag string, typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && structPlanOf(tag, typ).hasOption("bytes")
+}
+
+// byteEncodingOf returns the encoding of the bytes of the struct field planned
+// by field, i.e. its bytes tag option, or def. It reports false for unknown
+// encoding names.
+func byteEncodingOf(field *fieldPlan, def ByteEncoding) (ByteEncoding, bool) {
+	name, ok := field.option("bytes")
+	if !ok {
+		return def, true
+	}
+	e, ok := byteEncodingNames[name]
+	return e, ok
+}
+
+// byteEncoding returns the encoding of the bytes decoded into target, or of
+// the bytes of node decoded into the string target.
+func (dec *Decoding) byteEncoding(node *Tree, target DecodeTarget) (ByteEncoding, error) {
+	e, ok := byteEncodingOf(target.field, dec.Bytes)
+	if !ok {
+		name, _ := target.field.option("bytes")
+		return 0, newDecodeErrorf(
+			ErrInvalidOption,
+			node,
+			target,
+			"unknown byte encoding %q", name,
+		)
+	}
+	return e, nil
+}
+
+// intoBytesFromString decodes the string node into the byte slice or array
+// target using the byte encoding of target. Arrays must have the length of the
+// decoded bytes.
+func (dec *Decoding) intoBytesFromString(node *Tree, target DecodeTarget) error {
+	e, err := dec.byteEncoding(node, target)
+	if err != nil {
+		return err
+	}
+	b, err := e.decode(node.Value().String())
+	if err != nil {
+		return newDecodeErrorf(
+			ErrTypeMismatch,
+			node,
+			target,
+			"cannot decode string into %s: %w", target.Value.Type(), err,
+		)
+	}
+
+	typ := target.Value.Type()
+	if typ.Kind() == reflect.Array {
+		if len(b) != typ.Len() {
+			return newDecodeErrorf(
+				ErrTypeMismatch,
+				node,
+				target,
+				"cannot decode %d bytes into %s", len(b), typ,
+			)
+		}
+		setBytes(target.Value, b)
+		return nil
+	}
+	if typ.Elem() == byteType {
+		target.Value.Set(reflect.ValueOf(b).Convert(typ))
+		return nil
+	}
+	dst := reflect.MakeSlice(typ, len(b), len(b))
+	setBytes(dst, b)
+	target.Value.Set(dst)
+	return nil
+}
+
+// intoStringFromBytes decodes the byte slice or array node into the string
+// target using the byte encoding of target.
+func (dec *Decoding) intoStringFromBytes(node *Tree, target DecodeTarget) error {
+	e, err := dec.byteEncoding(node, target)
+	if err != nil {
+		return err
+	}
+	target.Value.SetString(e.encode(bytesOf(node.Value())))
+	return nil
+}
+
+// encodeBytes makes the node of a byte slice or array hold its string
+// representation in the encoding e, or restores the bytes for BytesRaw.
+func (t *Tree) encodeBytes(e ByteEncoding) {
+	t.val = t.source()
+	if t.ext != nil {
+		t.ext.orig = reflect.Value{}
+	}
+	if e == BytesRaw {
+		return
+	}
+	t.extra().orig = t.val
+	t.val = reflect.ValueOf(e.encode(bytesOf(t.val)))
+	t.clearRef()
+}
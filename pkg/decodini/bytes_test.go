@@ -0,0 +1,165 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type byteRecord struct {
+	Data []byte  `decodini:"data"`
+	ID   [4]byte `decodini:"id,bytes=hex"`
+	Raw  []byte  `decodini:"raw,bytes=raw"`
+	Key  []byte  `decodini:"key,bytes=base64url"`
+}
+
+func TestDecode_ByteEncodings(t *testing.T) {
+	a := assert.New(t)
+
+	dec := &Decoding{StructTag: "decodini", Bytes: BytesBase64}
+	from := map[string]any{
+		"data": "aGVsbG8=",
+		"id":   "DEADbeef",
+		"raw":  "aGVsbG8=",
+		"key":  "-_8=",
+	}
+
+	to, err := Decode[byteRecord](dec, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(byteRecord{
+		Data: []byte("hello"),
+		ID:   [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Raw:  []byte("aGVsbG8="),
+		Key:  []byte{0xfb, 0xff},
+	}, to)
+
+	toRaw, err := Decode[byteRecord](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal([]byte("aGVsbG8="), toRaw.Data)
+}
+
+func TestDecode_ByteEncodings_InvalidOffset(t *testing.T) {
+	a := assert.New(t)
+
+	dec := &Decoding{StructTag: "decodini", Bytes: BytesBase64}
+	from := map[string]any{"data": "aGV*bG8=", "id": "deadbeef", "raw": "", "key": ""}
+
+	_, err := Decode[byteRecord](dec, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.ErrorContains(err, "invalid base64 at offset 3")
+
+	from = map[string]any{"data": "", "id": "deadbexf", "raw": "", "key": ""}
+	_, err = Decode[byteRecord](dec, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.EqualError(
+		err,
+		"decodini: decode: failed at id: cannot decode string into [4]uint8: invalid hex at offset 6: encoding/hex: invalid byte: U+0078 'x'",
+	)
+
+	from = map[string]any{"data": "", "id": "dead", "raw": "", "key": ""}
+	_, err = Decode[byteRecord](dec, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.ErrorContains(err, "cannot decode 2 bytes into [4]uint8")
+}
+
+func TestDecode_Bytes_to_String(t *testing.T) {
+	a := assert.New(t)
+
+	dec := &Decoding{StructTag: "decodini", Bytes: BytesHex}
+	to, err := Decode[string](dec, Encode(nil, []byte{0xca, 0xfe}))
+	a.NoError(err)
+	a.Equal("cafe", to)
+
+	toRaw, err := Decode[string](nil, Encode(nil, []byte("hi")))
+	a.NoError(err)
+	a.Equal("hi", toRaw)
+}
+
+func TestEncode_ByteEncodings(t *testing.T) {
+	a := assert.New(t)
+
+	tr, err := NewTransmutation(WithBytes(BytesBase64))
+	a.NoError(err)
+
+	from := byteRecord{
+		Data: []byte("hello"),
+		ID:   [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Raw:  []byte("raw"),
+		Key:  []byte{0xfb, 0xff},
+	}
+	to, err := Transmute[map[string]any](&tr, from)
+	a.NoError(err)
+	a.Equal(map[string]any{
+		"data": "aGVsbG8=",
+		"id":   "deadbeef",
+		"raw":  []byte("raw"),
+		"key":  "-_8=",
+	}, to)
+
+	// The raw bytes in the map are no struct field and thus encoded by
+	// default, but not decoded by their tag option.
+	back, err := Transmute[byteRecord](&tr, to)
+	a.NoError(err)
+	a.Equal(from.Data, back.Data)
+	a.Equal(from.ID, back.ID)
+	a.Equal([]byte("cmF3"), back.Raw)
+
+	tm, err := NewTransmuter[byteRecord, map[string]any](&tr)
+	a.NoError(err)
+
+	compiled, err := tm.Run(from)
+	a.NoError(err)
+	a.Equal(to, compiled)
+
+	_, err = NewTransmutation(WithBytes(ByteEncoding(42)))
+	a.ErrorIs(err, ErrInvalidOption)
+}
+
+type namedByte byte
+
+func TestTransmute_NamedBytes(t *testing.T) {
+	a := assert.New(t)
+
+	s, err := Transmute[[]namedByte](nil, "abc")
+	a.NoError(err)
+	a.Equal([]namedByte{'a', 'b', 'c'}, s)
+
+	arr, err := Transmute[[3]namedByte](nil, "abc")
+	a.NoError(err)
+	a.Equal([3]namedByte{'a', 'b', 'c'}, arr)
+
+	str, err := Transmute[string](nil, []namedByte{'a', 'b', 'c'})
+	a.NoError(err)
+	a.Equal("abc", str)
+
+	str, err = Transmute[string](nil, [3]namedByte{'a', 'b', 'c'})
+	a.NoError(err)
+	a.Equal("abc", str)
+
+	tr, err := NewTransmutation(WithBytes(BytesHex))
+	a.NoError(err)
+
+	str, err = Transmute[string](&tr, [2]namedByte{0xca, 0xfe})
+	a.NoError(err)
+	a.Equal("cafe", str)
+
+	arr2, err := Transmute[[2]namedByte](&tr, "cafe")
+	a.NoError(err)
+	a.Equal([2]namedByte{0xca, 0xfe}, arr2)
+
+	s, err = Transmute[[]namedByte](&tr, "cafe")
+	a.NoError(err)
+	a.Equal([]namedByte{0xca, 0xfe}, s)
+
+	toSlice, err := NewTransmuter[string, []namedByte](nil)
+	a.NoError(err)
+	s, err = toSlice.Run("abc")
+	a.NoError(err)
+	a.Equal([]namedByte{'a', 'b', 'c'}, s)
+
+	fromArray, err := NewTransmuter[[3]namedByte, string](nil)
+	a.NoError(err)
+	str, err = fromArray.Run([3]namedByte{'a', 'b', 'c'})
+	a.NoError(err)
+	a.Equal("abc", str)
+}
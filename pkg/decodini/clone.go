@@ -0,0 +1,205 @@
+package decodini
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Clone returns a deep copy of v. It encodes v into a Tree with CycleRef and
+// decodes the tree into a new T, so struct tags apply like they do for
+// Transmute. Pointers, maps and slices that are shared within v are shared
+// within the copy as well, which also preserves pointer cycles. Interfaces
+// hold copies of the same dynamic types as in v.
+//
+// Struct fields that are not part of the tree, i.e. unexported fields and
+// fields excluded by their tag, are copied like by an assignment, i.e.
+// shallowly. This keeps the state of structs such as time.Time. Channels and
+// functions are copied shallowly as well.
+//
+// Only the WithTag, WithMaxDepth, WithMaxElements and WithMaxNodes options
+// apply, exceeding a limit is reported as a *DecodeError. Other options are
+// rejected with ErrInvalidOption.
+func Clone[T any](v T, opts ...Option) (T, error) {
+	var to T
+
+	tr, err := NewTransmutation(opts...)
+	if err != nil {
+		return to, err
+	}
+	if err := validateCloneOptions(tr); err != nil {
+		return to, err
+	}
+
+	enc := tr.Encoding
+	enc.Cycles = CycleRef
+	dec := tr.Decoding
+	dec.Decoder = dec.cloneDecoder
+
+	state := dec.newState(context.Background())
+	state.shared = make(map[nodeRef]reflect.Value)
+	target := DecodeTarget{Value: reflect.ValueOf(&to).Elem(), state: state}
+	if err := dec.into(Encode(&enc, reflect.ValueOf(&v).Elem()), target); err != nil {
+		var zero T
+		return zero, err
+	}
+	return to, nil
+}
+
+// validateCloneOptions reports an error if tr is configured by options other
+// than the struct tag and the limits that Clone applies.
+func validateCloneOptions(tr Transmutation) error {
+	enc, dec := defaultEncoding, defaultDecoding
+	enc.StructTag = tr.Decoding.StructTag
+	dec.StructTag = tr.Decoding.StructTag
+	dec.MaxDepth = tr.Decoding.MaxDepth
+	dec.MaxElements = tr.Decoding.MaxElements
+	dec.MaxNodes = tr.Decoding.MaxNodes
+	if !reflect.DeepEqual(enc, tr.Encoding) || !reflect.DeepEqual(dec, tr.Decoding) {
+		return fmt.Errorf(
+			"%w: Clone only supports the WithTag, WithMaxDepth, WithMaxElements "+
+				"and WithMaxNodes options",
+			ErrInvalidOption,
+		)
+	}
+	return nil
+}
+
+// cloneDecoder is the Decoding.Decoder of Clone, which decodes node into the
+// value of the same type that node was encoded from. Pointers are shared by
+// Decoding.into, as decoders do not see them.
+func (dec *Decoding) cloneDecoder(node *Tree, target DecodeTarget) Decoder {
+	switch target.Value.Kind() {
+	case reflect.Interface:
+		return dec.cloneInterface
+	case reflect.Map, reflect.Slice:
+		return dec.cloneShared
+	case reflect.Struct:
+		return dec.cloneStruct
+	case reflect.Array:
+		if node.kind() == reflect.Array && node.NumChildren() == uint(target.Value.Len()) {
+			return dec.cloneArray
+		}
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return cloneShallow
+	}
+	return nil
+}
+
+// cloneInterface decodes node into a new value of the dynamic type it was
+// encoded from, and stores the value in the interface target.
+func (dec *Decoding) cloneInterface(node *Tree, target DecodeTarget) error {
+	typ := node.Value().Type()
+	if orig := node.original(); orig.IsValid() {
+		typ = orig.Type()
+	}
+	if ref := node.ref(); ref.typ != nil && ref.typ.Kind() == reflect.Pointer &&
+		ref.typ.Elem() == typ {
+		// The node was reached through a pointer, see Tree.setPointerRef.
+		typ = ref.typ
+	}
+	if !typ.AssignableTo(target.Value.Type()) {
+		return dec.intoKind(node, target)
+	}
+
+	val := reflect.New(typ).Elem()
+	elem := target
+	elem.Value = val
+	if err := dec.into(node, elem); err != nil {
+		return err
+	}
+	target.Value.Set(val)
+	return nil
+}
+
+// cloneShared decodes the map or slice node into target, unless the memory
+// node refers to was already decoded into a value of the same type.
+func (dec *Decoding) cloneShared(node *Tree, target DecodeTarget) error {
+	if val, ok := target.state.sharedValue(node, target.Value.Type()); ok {
+		target.Value.Set(val)
+		return nil
+	}
+	if err := dec.intoKind(node, target); err != nil {
+		return err
+	}
+	target.state.share(node, target.Value)
+	return nil
+}
+
+// cloneStruct assigns the struct node was encoded from to target, so that the
+// fields without node keep their value, and decodes the fields with a node.
+func (dec *Decoding) cloneStruct(node *Tree, target DecodeTarget) error {
+	if src := node.Value(); src.Type() == target.Value.Type() {
+		target.Value.Set(src)
+		// The fields decoded from the tree must not refer to the memory of
+		// the source, which decoding would write to.
+		zeroFields(dec.StructTag, target.Value)
+	}
+	return dec.intoKind(node, target)
+}
+
+// cloneArray decodes the children of the array node into the elements of the
+// array target of the same length, which Decoding.into does not support.
+func (dec *Decoding) cloneArray(node *Tree, target DecodeTarget) error {
+	for child := range node.Children() {
+		i := child.Name().(int)
+		if err := dec.into(child, target.sub(i, target.Value.Index(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zeroFields sets the settable fields of the struct val that the plan of its
+// type includes to their zero value.
+func zeroFields(tag string, val reflect.Value) {
+	for _, field := range structPlanOf(tag, val.Type()).fields {
+		f := val.Field(field.index[0])
+		switch {
+		case !f.CanSet():
+		case field.embedded && f.Kind() == reflect.Struct:
+			zeroFields(tag, f)
+		default:
+			f.SetZero()
+		}
+	}
+}
+
+// cloneShallow assigns the value node holds to target.
+func cloneShallow(node *Tree, target DecodeTarget) error {
+	if !node.Value().Type().AssignableTo(target.Value.Type()) {
+		return newDecodeErrorf(
+			ErrTypeMismatch,
+			node,
+			target,
+			"cannot copy %s into %s", node.Value().Type(), target.Value.Type(),
+		)
+	}
+	target.Value.Set(node.Value())
+	return nil
+}
+
+// sharedValue returns the value that the memory node refers to was decoded
+// into by Clone, if it has the type typ.
+func (s *decodeState) sharedValue(node *Tree, typ reflect.Type) (reflect.Value, bool) {
+	if s == nil || s.shared == nil {
+		return reflect.Value{}, false
+	}
+	ref := node.ref()
+	if ref.ptr == 0 || ref.typ != typ {
+		return reflect.Value{}, false
+	}
+	val, ok := s.shared[ref]
+	return val, ok
+}
+
+// share records that the memory node refers to was decoded into val by
+// Clone, if val has the type of the memory.
+func (s *decodeState) share(node *Tree, val reflect.Value) {
+	if s == nil || s.shared == nil {
+		return
+	}
+	if ref := node.ref(); ref.ptr != 0 && ref.typ == val.Type() {
+		s.shared[ref] = val
+	}
+}
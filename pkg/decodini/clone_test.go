@@ -0,0 +1,172 @@
+package decodini
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	type inner struct {
+		Tags []string
+	}
+	type outer struct {
+		Name   string
+		Inner  *inner
+		Values map[string]any
+		Array  [2]*int
+		Any    any
+		hidden []int
+	}
+
+	a := assert.New(t)
+
+	n := 42
+	from := outer{
+		Name:   "foo",
+		Inner:  &inner{Tags: []string{"a", "b"}},
+		Values: map[string]any{"x": []any{1, "y"}},
+		Array:  [2]*int{&n, nil},
+		Any:    &inner{Tags: []string{"c"}},
+		hidden: []int{1, 2, 3},
+	}
+
+	to, err := Clone(from)
+	a.NoError(err)
+	a.Equal(from, to)
+
+	a.NotSame(from.Inner, to.Inner)
+	a.NotSame(&from.Inner.Tags[0], &to.Inner.Tags[0])
+	a.NotSame(from.Array[0], to.Array[0])
+	a.NotSame(from.Any, to.Any)
+	// Unexported fields are not part of the tree and are copied shallowly.
+	a.Same(&from.hidden[0], &to.hidden[0])
+
+	to.Values["x"].([]any)[0] = 2
+	a.Equal(1, from.Values["x"].([]any)[0])
+}
+
+func TestClone_PreservesAliasing(t *testing.T) {
+	type shared struct {
+		A, B  *int
+		M1    map[string]int
+		M2    map[string]int
+		S1    []int
+		S2    []int
+		Iface any
+	}
+
+	a := assert.New(t)
+
+	n := 1
+	m := map[string]int{"a": 1}
+	s := []int{1, 2}
+	from := shared{A: &n, B: &n, M1: m, M2: m, S1: s, S2: s, Iface: &n}
+
+	to, err := Clone(from)
+	a.NoError(err)
+	a.NotSame(from.A, to.A)
+	a.Same(to.A, to.B)
+	a.Same(to.A, to.Iface)
+
+	to.M1["b"] = 2
+	a.Equal(2, to.M2["b"])
+	a.NotContains(m, "b")
+
+	to.S1[0] = 3
+	a.Equal(3, to.S2[0])
+	a.Equal(1, s[0])
+}
+
+func TestClone_Cycle(t *testing.T) {
+	a := assert.New(t)
+
+	from := cyclicList()
+	to, err := Clone(from)
+	a.NoError(err)
+	a.NotSame(from, to)
+	if a.NotNil(to.Next) {
+		a.Same(to, to.Next.Prev)
+		a.Equal(2, to.Next.Value)
+	}
+}
+
+func TestClone_Limits(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := Clone([]int{1, 2, 3}, WithMaxElements(2))
+	a.ErrorIs(err, ErrLimitExceeded)
+
+	_, err = Clone(map[string][]int{"a": {1}}, WithMaxDepth(1))
+	a.ErrorIs(err, ErrLimitExceeded)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "a"}, Index(0)}, decErr.Path())
+	}
+
+	_, err = Clone(1, WithMaxNodes(-1))
+	a.ErrorIs(err, ErrInvalidOption)
+}
+
+func TestClone_ForeignTypes(t *testing.T) {
+	type guarded struct {
+		mu      *sync.Mutex
+		Created time.Time
+		At      *time.Time
+	}
+
+	a := assert.New(t)
+
+	now := time.Now()
+	from := &guarded{mu: new(sync.Mutex), Created: now, At: &now}
+
+	to, err := Clone(from)
+	a.NoError(err)
+	a.Same(time.Local, to.Created.Location())
+	a.True(to.Created.Equal(now))
+	a.NotSame(from.At, to.At)
+	a.True(to.At.Equal(now))
+	a.Same(from.mu, to.mu)
+}
+
+func TestClone_StructTags(t *testing.T) {
+	type tagged struct {
+		Tags    []string       `decodini:"tags,sep=','"`
+		Skipped map[string]int `decodini:"-"`
+		Renamed *int           `json:"renamed"`
+	}
+
+	a := assert.New(t)
+
+	n := 1
+	from := tagged{Tags: []string{"a", "b"}, Skipped: map[string]int{"a": 1}, Renamed: &n}
+	to, err := Clone(from)
+	a.NoError(err)
+	a.Equal(from, to)
+	a.NotSame(&from.Tags[0], &to.Tags[0])
+	a.NotSame(from.Renamed, to.Renamed)
+
+	// Fields excluded by their tag are copied shallowly.
+	to.Skipped["b"] = 2
+	a.Contains(from.Skipped, "b")
+
+	to, err = Clone(from, WithTag("json"))
+	a.NoError(err)
+	a.Equal(from, to)
+}
+
+func TestClone_UnsupportedOptions(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := Clone(1, WithSets())
+	a.ErrorIs(err, ErrInvalidOption)
+
+	_, err = Clone(1, WithDecodeTag("json"))
+	a.ErrorIs(err, ErrInvalidOption)
+
+	_, err = Clone(1, WithMaxDepth(3), WithMaxNodes(10))
+	a.NoError(err)
+}
@@ -1,6 +1,8 @@
 package decodini
 
 import (
+	"context"
+	"iter"
 	"reflect"
 	"unicode/utf16"
 )
@@ -19,6 +21,75 @@ type Decoding struct {
 	Decoder func(tr *Tree, target DecodeTarget) Decoder
 
 	Unmatched func(tr *Tree, target DecodeTarget) (*Tree, error)
+
+	// Warn is called for every non-fatal Warning encountered while decoding.
+	Warn func(w Warning)
+
+	// MaxDepth is the maximum nesting depth of the decoded values. Zero means
+	// no limit.
+	MaxDepth int
+
+	// MaxElements is the maximum number of elements of a single slice, array,
+	// map or struct in the source tree. Slices decoded from maps keyed by
+	// indices count their largest index plus one. Zero means no limit.
+	MaxElements int
+
+	// MaxNodes is the maximum total number of nodes visited while decoding.
+	// Zero means no limit.
+	MaxNodes int
+
+	// MaxStringLength is the maximum length in bytes of a source string. Zero
+	// means no limit.
+	MaxStringLength int
+
+	// Strict reports an error for source keys that do not match any field of
+	// the target struct.
+	Strict bool
+
+	// RejectHoles reports an error for indices that are missing when decoding
+	// a map with index keys into a slice, instead of leaving them zero.
+	RejectHoles bool
+
+	// RejectDuplicates reports an error for duplicate elements when decoding
+	// a slice into a set-shaped map, instead of ignoring them.
+	RejectDuplicates bool
+
+	// Singletons decodes scalars into slices as their only element, and
+	// slices with a single element into scalars. Strings are still decoded
+	// into slices of bytes, runes and other integers by their encoding.
+	Singletons bool
+
+	// Separator splits strings decoded into slices, e.g. "a,b,c" into
+	// []string{"a", "b", "c"}, unless the struct field of the slice has a sep
+	// tag option. The pieces are parsed like map keys. Byte and rune slices
+	// are not split. Empty means no splitting.
+	Separator string
+
+	// Delimiting configures the quoting and escaping of delimited strings.
+	Delimiting Delimiting
+
+	// Entries converts between maps and slices of entry structs with the
+	// given key and value fields, like the entries tag option does for struct
+	// fields. It applies to all slices of structs decoded from maps, and to
+	// all maps decoded from slices, unless their struct field has the entries
+	// or keyby tag option. The zero value disables it.
+	Entries EntryNames
+
+	// Bytes is the encoding of strings decoded into byte slices and arrays,
+	// and of byte slices and arrays decoded into strings. Defaults to
+	// BytesRaw.
+	Bytes ByteEncoding
+
+	// FoldEnums matches the names of registered enums case-insensitively if
+	// no name matches exactly, see RegisterEnum.
+	FoldEnums bool
+
+	// Parallelism is the maximum number of goroutines decoding the elements of
+	// large slices and maps concurrently. Values below 2 disable parallel
+	// decoding. Element order, errors and warnings are the same as when
+	// decoding sequentially, but Decoder, Unmatched and Warn must be safe for
+	// concurrent use. Parallel decoding is disabled if MaxNodes is set.
+	Parallelism int
 }
 
 var defaultDecoding = Decoding{
@@ -27,11 +98,25 @@ var defaultDecoding = Decoding{
 }
 
 func DecodeInto(dec *Decoding, tr *Tree, into any) error {
+	return DecodeIntoContext(context.Background(), dec, tr, into)
+}
+
+// DecodeIntoContext is like DecodeInto, but aborts decoding as soon as ctx is
+// done. The context is available to custom decoders via DecodeTarget.Context.
+func DecodeIntoContext(
+	ctx context.Context,
+	dec *Decoding,
+	tr *Tree,
+	into any,
+) error {
 	if dec == nil {
 		dec = &defaultDecoding
 	}
 	if dec.StructTag == "" {
-		dec.StructTag = defaultDecoding.StructTag
+		// dec may be shared with other goroutines and must not be modified.
+		withTag := *dec
+		withTag.StructTag = defaultDecoding.StructTag
+		dec = &withTag
 	}
 
 	if tr == nil {
@@ -43,15 +128,43 @@ func DecodeInto(dec *Decoding, tr *Tree, into any) error {
 		rVal = reflect.ValueOf(into)
 	}
 
-	return dec.into(tr, DecodeTarget{Value: rVal})
+	return dec.into(tr, DecodeTarget{Value: rVal, state: dec.newState(ctx)})
+}
+
+// newState returns the state of a new decoding run aborted as soon as ctx is
+// done.
+func (dec *Decoding) newState(ctx context.Context) *decodeState {
+	state := &decodeState{ctx: ctx}
+	if dec.Parallelism > 1 {
+		state.sem = make(chan struct{}, dec.Parallelism-1)
+	}
+	return state
 }
 
 func Decode[T any](dec *Decoding, tr *Tree) (T, error) {
+	return DecodeContext[T](context.Background(), dec, tr)
+}
+
+// DecodeContext is like Decode, but aborts decoding as soon as ctx is done.
+func DecodeContext[T any](ctx context.Context, dec *Decoding, tr *Tree) (T, error) {
 	var to T
-	return to, DecodeInto(dec, tr, &to)
+	return to, DecodeIntoContext(ctx, dec, tr, &to)
 }
 
 func (dec *Decoding) into(node *Tree, target DecodeTarget) error {
+	if cycle := node.Cycle(); cycle != nil {
+		if target.Value.Kind() != reflect.Pointer {
+			return dec.cycleError(node, target)
+		}
+		ptr := target.state.ref(cycle)
+		if ptr.IsValid() &&
+			ptr.Type().AssignableTo(target.Value.Type()) &&
+			target.Value.CanSet() {
+			target.Value.Set(ptr)
+			return nil
+		}
+	}
+
 	if target.Value.Kind() == reflect.Pointer {
 		if node.IsNil() {
 			if target.Value.CanSet() {
@@ -59,7 +172,12 @@ func (dec *Decoding) into(node *Tree, target DecodeTarget) error {
 				return nil
 			}
 			if target.Value.IsNil() {
-				return newDecodeErrorf(node, target, "cannot decode into unsettable value")
+				return newDecodeErrorf(
+					ErrUnsettable,
+					node,
+					target,
+					"cannot decode into unsettable value",
+				)
 			}
 
 			target.Value = target.Value.Elem()
@@ -68,18 +186,40 @@ func (dec *Decoding) into(node *Tree, target DecodeTarget) error {
 
 		if target.Value.IsNil() {
 			if !target.Value.CanSet() {
-				return newDecodeErrorf(node, target, "cannot decode into unsettable value")
+				return newDecodeErrorf(
+					ErrUnsettable,
+					node,
+					target,
+					"cannot decode into unsettable value",
+				)
 			}
 
+			if ptr, ok := target.state.sharedValue(node, target.Value.Type()); ok {
+				target.Value.Set(ptr)
+				return nil
+			}
 			target.Value.Set(reflect.New(target.Value.Type().Elem()))
+			target.state.share(node, target.Value)
+		}
+		if node.enc.Cycles == CycleRef && node.ref().ptr != 0 && target.state != nil {
+			target.state.setRef(node, target.Value)
 		}
 
 		target.Value = target.Value.Elem()
 		return dec.into(node, target)
 	}
 
+	if err := dec.checkLimits(node, target); err != nil {
+		return err
+	}
+
 	if !target.Value.CanSet() {
-		return newDecodeErrorf(node, target, "cannot decode into unsettable value")
+		return newDecodeErrorf(
+			ErrUnsettable,
+			node,
+			target,
+			"cannot decode into unsettable value",
+		)
 	}
 
 	if node.IsNil() {
@@ -93,6 +233,34 @@ func (dec *Decoding) into(node *Tree, target DecodeTarget) error {
 		}
 	}
 
+	if target.Value.Kind() == reflect.Interface && !node.IsPrimitive() {
+		return dec.intoInterface(node, target)
+	}
+
+	return dec.intoKind(node, target)
+}
+
+// cycleError reports that node, which closes a pointer cycle, cannot be
+// decoded into target.
+func (dec *Decoding) cycleError(node *Tree, target DecodeTarget) error {
+	path := node.Cycle().Path().String()
+	if path == "" {
+		path = "<root>"
+	}
+	if node.enc.Cycles == CycleRef {
+		return newDecodeErrorf(
+			ErrCycle,
+			node,
+			target,
+			"cannot resolve back-reference to %s into %s",
+			path, target.Value.Type(),
+		)
+	}
+	return newDecodeErrorf(ErrCycle, node, target, "cycle back to %s", path)
+}
+
+// intoKind decodes node into target based on the kind of the target.
+func (dec *Decoding) intoKind(node *Tree, target DecodeTarget) error {
 	if target.IsPrimitive() {
 		return dec.intoScalar(node, target)
 	}
@@ -111,6 +279,7 @@ func (dec *Decoding) into(node *Tree, target DecodeTarget) error {
 		return dec.intoMap(node, target)
 	default:
 		return newDecodeErrorf(
+			ErrUnsupportedKind,
 			node,
 			target,
 			"cannot decode into %s", target.Value.Type(),
@@ -118,7 +287,53 @@ func (dec *Decoding) into(node *Tree, target DecodeTarget) error {
 	}
 }
 
+// intoInterface decodes a non-primitive node into an interface target by
+// decoding it into a fresh value of the node's type.
+func (dec *Decoding) intoInterface(node *Tree, target DecodeTarget) error {
+	typ := node.Value().Type()
+	// Tuples, sets and entries are decoded like the slices they are encoded
+	// as.
+	switch {
+	case node.tuple:
+		typ = reflect.TypeFor[[]any]()
+	case node.set:
+		typ = reflect.SliceOf(typ.Key())
+	case node.entries() != (EntryNames{}):
+		typ = reflect.TypeFor[[]map[string]any]()
+	}
+	if !typ.AssignableTo(target.Value.Type()) {
+		return newDecodeErrorf(
+			ErrTypeMismatch,
+			node,
+			target,
+			"cannot decode %s into %s", typ, target.Value.Type(),
+		)
+	}
+
+	sub := target
+	sub.Value = reflect.New(typ).Elem()
+	if err := dec.intoKind(node, sub); err != nil {
+		return err
+	}
+
+	target.Value.Set(sub.Value)
+	return nil
+}
+
 func (dec *Decoding) intoScalar(node *Tree, target DecodeTarget) error {
+	if e := enumOf(target.Value.Type()); e != nil && isEnumSource(e, node) {
+		return dec.intoEnum(e, node, target)
+	}
+	if orig := node.original(); orig.IsValid() && isNumeric(orig.Kind()) &&
+		isNumeric(target.Value.Kind()) {
+		// Enum values are decoded into other numbers by value, not by name.
+		src := *node
+		src.val, src.ext = orig, nil
+		node = &src
+	}
+	if target.Value.Kind() == reflect.String && isByteSequence(node.Value().Type()) {
+		return dec.intoStringFromBytes(node, target)
+	}
 	if target.Value.Kind() == reflect.String {
 		switch node.Value().Kind() {
 		case reflect.Slice, reflect.Array:
@@ -167,49 +382,113 @@ func (dec *Decoding) intoScalar(node *Tree, target DecodeTarget) error {
 	}
 
 	if !node.IsPrimitive() {
+		if dec.Singletons && (node.kind() == reflect.Slice || node.kind() == reflect.Array) {
+			return dec.intoScalarFromSingleton(node, target)
+		}
 		return newDecodeErrorf(
+			ErrTypeMismatch,
 			node,
 			target,
 			"leaf decoder can't decode non-leaf %s", node.Value().Kind(),
 		)
 	}
 
-	target.Value.Set(node.Value())
-	return nil
+	return dec.setScalar(node, target)
+}
+
+// setScalar assigns the primitive value of node to target. Numeric values are
+// converted between numeric kinds as long as they fit into the target type.
+func (dec *Decoding) setScalar(node *Tree, target DecodeTarget) error {
+	val := node.Value()
+	typ := target.Value.Type()
+
+	switch {
+	case val.Type().AssignableTo(typ):
+		target.Value.Set(val)
+		return nil
+
+	case isNumeric(val.Kind()) && isNumeric(typ.Kind()):
+		if overflows(val, typ) {
+			return newDecodeErrorf(
+				ErrOverflow,
+				node,
+				target,
+				"%v overflows %s", val, typ,
+			)
+		}
+		conv := val.Convert(typ)
+		if !isNaN(val) && !conv.Convert(val.Type()).Equal(val) {
+			dec.warn(
+				target,
+				node,
+				WarningLossyConversion,
+				"%v converted to %s as %v", val, typ, conv,
+			)
+		}
+		target.Value.Set(conv)
+		return nil
+
+	case val.Kind() == typ.Kind() && val.Type().ConvertibleTo(typ):
+		target.Value.Set(val.Convert(typ))
+		return nil
+
+	default:
+		return newDecodeErrorf(
+			ErrTypeMismatch,
+			node,
+			target,
+			"cannot decode %s into %s", val.Type(), typ,
+		)
+	}
 }
 
 func (dec *Decoding) intoStruct(node *Tree, target DecodeTarget) error {
-	switch node.Value().Kind() {
+	if dec.isTupleTarget(target) {
+		switch node.kind() {
+		case reflect.Slice, reflect.Array:
+			return dec.intoStructFromTuple(node, target)
+		default:
+			return newDecodeErrorf(
+				ErrTypeMismatch,
+				node,
+				target,
+				"cannot decode %s into tuple struct", node.kind(),
+			)
+		}
+	}
+
+	switch node.kind() {
 	case reflect.Struct, reflect.Map:
 		return dec.intoStructFromStructOrMap(node, target)
 	default:
 		return newDecodeErrorf(
+			ErrTypeMismatch,
 			node,
 			target,
-			"cannot decode %s into struct", node.Value().Kind(),
+			"cannot decode %s into struct", node.kind(),
 		)
 	}
 }
 
 func (dec *Decoding) intoStructFromStructOrMap(node *Tree, target DecodeTarget) error {
 	targetType := target.Value.Type()
-	for i := range target.Value.NumField() {
-		targetSF := targetType.Field(i)
-		if !includeStructField(dec.StructTag, targetSF) {
-			continue
-		}
-
-		targetName := structFieldName(dec.StructTag, targetSF)
+	plan := structPlanOf(dec.StructTag, targetType)
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		targetName := field.name
 
-		if targetSF.Anonymous {
-			from := node
+		if field.embedded {
+			from, inline := node, true
 			if child := node.Child(targetName); child != nil {
-				from = child
+				from, inline = child, false
 			}
 
-			sub := DecodeTarget{
-				Value:       target.Value.Field(i),
-				structField: &targetSF,
+			sub := target.sub(nil, target.Value.Field(field.index[0]))
+			sub.field = field
+			sub.inline = inline
+			if inline {
+				sub.depth = target.depth
+				sub.keyBy = target.keyBy
 			}
 			if err := dec.into(from, sub); err != nil {
 				return err
@@ -218,19 +497,34 @@ func (dec *Decoding) intoStructFromStructOrMap(node *Tree, target DecodeTarget)
 		}
 
 		from := node.Child(targetName)
-		sub := DecodeTarget{
-			Name:        targetName,
-			Value:       target.Value.Field(i),
-			structField: &targetSF,
+		if from != nil {
+			if msg, deprecated := field.opts.Get("deprecated"); deprecated {
+				dec.warnDeprecated(target, from, targetName, msg)
+			}
+		}
+
+		sub := target.sub(targetName, target.Value.Field(field.index[0]))
+		sub.field = field
+		if len(target.keyBy) > 0 && target.keyBy[0] == targetName {
+			if from == nil && len(target.keyBy) == 1 {
+				continue
+			}
+			sub.keyBy = target.keyBy[1:]
 		}
 
 		if from == nil {
 			if dec.Unmatched == nil {
-				return newDecodeErrorf(
+				err := newDecodeErrorf(
+					ErrUnmatchedField,
 					node.dummyChild(targetName),
 					target,
 					"struct field %s is unmatched in source tree", targetName,
 				)
+				err.Suggestions = suggest(
+					targetName,
+					missingNames(childNames(node), plan.names),
+				)
+				return err
 			}
 			uFrom, uErr := dec.Unmatched(from, sub)
 			if uErr != nil {
@@ -246,11 +540,46 @@ func (dec *Decoding) intoStructFromStructOrMap(node *Tree, target DecodeTarget)
 			return err
 		}
 	}
+
+	if dec.Strict && !target.inline {
+		return dec.checkUnknownFields(node, target)
+	}
+	return nil
+}
+
+// checkUnknownFields reports an error for the first child of node that does
+// not match any field of the target struct.
+func (dec *Decoding) checkUnknownFields(node *Tree, target DecodeTarget) error {
+	plan := structPlanOf(dec.StructTag, target.Value.Type())
+	for child := range node.Children() {
+		name, isStr := child.Name().(string)
+		if isStr && plan.hasName(name) {
+			continue
+		}
+
+		err := newDecodeErrorf(
+			ErrUnknownField,
+			child,
+			target,
+			"source key %v does not match any struct field", child.Name(),
+		)
+		if isStr {
+			err.Suggestions = suggest(name, missingNames(plan.names, childNames(node)))
+		}
+		return err
+	}
 	return nil
 }
 
 func (dec *Decoding) intoSlice(node *Tree, target DecodeTarget) error {
 	if node.Value().Kind() == reflect.String {
+		if sep, ok := dec.separator(target); ok {
+			return dec.intoSliceFromDelimited(node, target, sep)
+		}
+		if isByteSequence(target.Value.Type()) {
+			return dec.intoBytesFromString(node, target)
+		}
+
 		s := node.Value().String()
 		elemType := target.Value.Type().Elem()
 		elemKind := elemType.Kind()
@@ -282,16 +611,20 @@ func (dec *Decoding) intoSlice(node *Tree, target DecodeTarget) error {
 		}
 	}
 
-	switch node.Value().Kind() {
+	switch node.kind() {
 	case reflect.Slice, reflect.Array:
 		return dec.intoSliceFromSliceOrArray(node, target)
 	case reflect.Map:
 		return dec.intoSliceFromMap(node, target)
 	default:
+		if dec.Singletons && node.IsPrimitive() {
+			return dec.intoSliceFromScalar(node, target)
+		}
 		return newDecodeErrorf(
+			ErrTypeMismatch,
 			node,
 			target,
-			"cannot decode %s into slice", node.Value().Kind(),
+			"cannot decode %s into slice", node.kind(),
 		)
 	}
 }
@@ -308,50 +641,70 @@ func (dec *Decoding) intoSliceFromSliceOrArray(
 	}
 	typ := inferType(node, target)
 
-	for from := range node.Children() {
-		val := reflect.New(typ.Elem()).Elem()
-
-		subtarget := DecodeTarget{Name: from.Name(), Value: val}
-		err := dec.into(from, subtarget)
-		if err != nil {
-			return err
-		}
+	return dec.decodeElements(
+		target,
+		node.Children(),
+		nChildren,
+		func(_ int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			val := reflect.New(typ.Elem()).Elem()
+			return val, dec.into(from, target.sub(from.Name(), val))
+		},
+		func(_ int, from *Tree, val reflect.Value) {
+			target.Value.Index(from.Name().(int)).Set(val)
+		},
+	)
+}
 
-		target.Value.Index(from.Name().(int)).Set(val)
+func (dec *Decoding) intoSliceFromMap(node *Tree, target DecodeTarget) error {
+	if names, ok := dec.entries(target); ok {
+		return dec.intoSliceFromEntries(node, target, names)
 	}
-
-	return nil
+	if path, ok := dec.keyBy(target); ok {
+		return dec.intoSliceFromMapByKey(node, target, path)
+	}
+	if children, ok := indexedChildren(node); ok {
+		return dec.intoSliceFromIndexMap(node, target, children)
+	}
+	return dec.intoSliceFromChildren(node, target, dec.into)
 }
 
-func (dec *Decoding) intoSliceFromMap(node *Tree, target DecodeTarget) error {
+// intoSliceFromChildren decodes the children of the map or struct node into
+// the elements of the slice target in their order, using decode.
+func (dec *Decoding) intoSliceFromChildren(
+	node *Tree,
+	target DecodeTarget,
+	decode func(from *Tree, elem DecodeTarget) error,
+) error {
 	nChildren := int(node.NumChildren())
-	if target.Value.IsNil() {
+	if target.Value.IsNil() || target.Value.Len() < nChildren {
 		target.Value.Set(
 			reflect.MakeSlice(target.Value.Type(), nChildren, nChildren),
 		)
 	}
 	typ := inferType(node, target)
 
-	i := 0
-	for from := range node.Children() {
-		val := reflect.New(typ.Elem()).Elem()
-
-		subtarget := DecodeTarget{Name: i, Value: val}
-		err := dec.into(from, subtarget)
-		if err != nil {
-			return err
-		}
-
-		target.Value.Index(i).Set(val)
-		i++
-	}
-
-	return nil
+	return dec.decodeElements(
+		target,
+		node.Children(),
+		nChildren,
+		func(i int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			val := reflect.New(typ.Elem()).Elem()
+			return val, decode(from, target.sub(i, val))
+		},
+		func(i int, _ *Tree, val reflect.Value) {
+			target.Value.Index(i).Set(val)
+		},
+	)
 }
 
 func (dec *Decoding) intoArray(node *Tree, target DecodeTarget) error {
+	if node.Value().Kind() == reflect.String && isByteSequence(target.Value.Type()) {
+		return dec.intoBytesFromString(node, target)
+	}
+
 	// TODO: implement array decoding
 	return newDecodeErrorf(
+		ErrUnsupportedKind,
 		node,
 		target,
 		"decodini does currently not support arrays",
@@ -359,38 +712,104 @@ func (dec *Decoding) intoArray(node *Tree, target DecodeTarget) error {
 }
 
 func (dec *Decoding) intoMap(node *Tree, target DecodeTarget) error {
-	switch node.Value().Kind() {
+	switch node.kind() {
 	case reflect.Map, reflect.Struct:
 		return dec.intoMapFromMapOrStruct(node, target)
+	case reflect.Slice, reflect.Array:
+		if names, ok := dec.entries(target); ok {
+			return dec.intoMapFromEntries(node, target, names)
+		}
+		if path, ok := dec.keyBy(target); ok {
+			return dec.intoMapFromSliceByKey(node, target, path)
+		}
+		if isSetType(target.Value.Type()) && isSetSource(node, target.Value.Type()) {
+			return dec.intoSetFromSlice(node, target)
+		}
+		return dec.intoMapFromSlice(node, target)
 	default:
 		return newDecodeErrorf(
+			ErrTypeMismatch,
 			node,
 			target,
-			"cannot decode %s into map", node.Value().Kind(),
+			"cannot decode %s into map", node.kind(),
 		)
 	}
 }
 
 func (dec *Decoding) intoMapFromMapOrStruct(node *Tree, target DecodeTarget) error {
+	return dec.intoMapFromChildren(
+		node,
+		target,
+		node.Children(),
+		int(node.NumChildren()),
+		sourceKeyType(node),
+		func(_ int, from *Tree) (any, *Tree) { return from.Name(), from },
+	)
+}
+
+// intoMapFromChildren decodes the n children of node into the map target,
+// storing them at the keys returned by keyOf along with the node that key
+// errors refer to. The keys are converted into the key type of target unless
+// srcKeyType is assignable to it, which is unknown if nil.
+func (dec *Decoding) intoMapFromChildren(
+	node *Tree,
+	target DecodeTarget,
+	children iter.Seq[*Tree],
+	n int,
+	srcKeyType reflect.Type,
+	keyOf func(i int, from *Tree) (any, *Tree),
+) error {
 	if target.Value.IsNil() {
-		target.Value.Set(
-			reflect.MakeMapWithSize(target.Value.Type(), int(node.NumChildren())),
-		)
+		target.Value.Set(reflect.MakeMapWithSize(target.Value.Type(), n))
 	}
 	typ := inferType(node, target)
+	keyType := target.Value.Type().Key()
 
-	for from := range node.Children() {
-		key := reflect.ValueOf(from.Name())
-		val := reflect.New(typ.Elem()).Elem()
+	// keys holds the converted keys if the source keys are not assignable to
+	// the key type.
+	var keys []reflect.Value
+	if srcKeyType == nil || !srcKeyType.AssignableTo(keyType) {
+		keys = make([]reflect.Value, n)
+	}
 
-		subtarget := DecodeTarget{Name: key.Interface(), Value: val}
-		err := dec.into(from, subtarget)
-		if err != nil {
-			return err
-		}
+	return dec.decodeElements(
+		target,
+		children,
+		n,
+		func(i int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			name, keyNode := keyOf(i, from)
+			if keys != nil {
+				key, err := convertKey(name, keyType, dec.FoldEnums)
+				if err != nil {
+					err.From, err.Into = keyNode, target
+					return reflect.Value{}, err
+				}
+				keys[i] = key
+			}
 
-		target.Value.SetMapIndex(key, val)
-	}
+			val := reflect.New(typ.Elem()).Elem()
+			return val, dec.into(from, target.sub(name, val))
+		},
+		func(i int, from *Tree, val reflect.Value) {
+			if keys != nil {
+				target.Value.SetMapIndex(keys[i], val)
+				return
+			}
+			name, _ := keyOf(i, from)
+			target.Value.SetMapIndex(reflect.ValueOf(name), val)
+		},
+	)
+}
 
-	return nil
+// sourceKeyType returns the type of the names of the children of the map or
+// struct node. The names of maps with enum keys are either names or values,
+// see mapKeyName.
+func sourceKeyType(node *Tree) reflect.Type {
+	if node.Value().Kind() != reflect.Map {
+		return reflect.TypeFor[string]()
+	}
+	if key := node.Value().Type().Key(); enumOf(key) == nil {
+		return key
+	}
+	return reflect.TypeFor[any]()
 }
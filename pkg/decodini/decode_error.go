@@ -1,52 +1,165 @@
 package decodini
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"reflect"
+	"slices"
+)
+
+// Error kinds attached to a DecodeError. They can be matched using errors.Is.
+var (
+	// ErrUnmatchedField is reported when a struct field has no counterpart in
+	// the source tree.
+	ErrUnmatchedField = errors.New("decodini: unmatched field")
+
+	// ErrTypeMismatch is reported when the source value cannot be represented
+	// by the target type.
+	ErrTypeMismatch = errors.New("decodini: type mismatch")
+
+	// ErrUnsettable is reported when the target value cannot be set.
+	ErrUnsettable = errors.New("decodini: unsettable value")
+
+	// ErrUnsupportedKind is reported when the target kind is not supported.
+	ErrUnsupportedKind = errors.New("decodini: unsupported kind")
+
+	// ErrUnknownField is reported in strict mode when a source key has no
+	// counterpart in the target struct.
+	ErrUnknownField = errors.New("decodini: unknown field")
+
+	// ErrLimitExceeded is reported when decoding exceeds one of the resource
+	// limits configured on Decoding.
+	ErrLimitExceeded = errors.New("decodini: limit exceeded")
+
+	// ErrOverflow is reported when a numeric source value does not fit into
+	// the numeric target type.
+	ErrOverflow = errors.New("decodini: overflow")
+
+	// ErrMissingIndex is reported if Decoding.RejectHoles is set and a map
+	// decoded into a slice lacks an index.
+	ErrMissingIndex = errors.New("decodini: missing index")
+
+	// ErrCycle is reported when a source node closes a pointer cycle that
+	// cannot be decoded.
+	ErrCycle = errors.New("decodini: cycle")
+
+	// ErrDuplicateKey is reported when two elements of a slice decoded into a
+	// map have the same key, or are the same element of a set if
+	// Decoding.RejectDuplicates is set.
+	ErrDuplicateKey = errors.New("decodini: duplicate key")
 )
 
 type DecodeError struct {
 	From *Tree
 	Into DecodeTarget
 	Err  error
+
+	// Kind is one of the Err* error kinds declared by this package, or nil if
+	// the error was not produced by decodini itself.
+	Kind error
+
+	// Suggestions holds the names that were most likely meant instead of the
+	// unmatched or unknown one, closest first.
+	Suggestions []string
+
+	// prefix is prepended to the path of From. Errors that are not associated
+	// with a Tree, i.e. those reported by generated code, only have a prefix.
+	prefix Path
 }
 
 var _ error = (*DecodeError)(nil)
 
-func newDecodeError(from *Tree, into DecodeTarget, err error) *DecodeError {
-	return &DecodeError{From: from, Into: into, Err: err}
+// NewDecodeError returns a DecodeError of the given kind at path that is not
+// associated with a Tree.
+//
+// NewDecodeError only exists for code generated by decodini-gen and should not
+// be called otherwise.
+func NewDecodeError(kind error, path Path, err error) *DecodeError {
+	return &DecodeError{Err: err, Kind: kind, prefix: slices.Clone(path)}
+}
+
+// PrefixPath returns a copy of err with segs prepended to its path if err is a
+// *DecodeError, or err itself otherwise. err is not modified.
+//
+// PrefixPath only exists for code generated by decodini-gen, which builds the
+// path of an error while returning from nested conversions, and should not be
+// called otherwise.
+func PrefixPath(err error, segs ...PathSegment) error {
+	decErr, ok := err.(*DecodeError)
+	if !ok {
+		return err
+	}
+	prefixed := *decErr
+	prefixed.prefix = append(slices.Clone(Path(segs)), decErr.prefix...)
+	return &prefixed
+}
+
+func newDecodeError(
+	kind error,
+	from *Tree,
+	into DecodeTarget,
+	err error,
+) *DecodeError {
+	return &DecodeError{From: from, Into: into, Err: err, Kind: kind}
 }
 
 func newDecodeErrorf(
+	kind error,
 	from *Tree,
 	into DecodeTarget,
 	format string,
 	args ...any,
 ) *DecodeError {
-	return newDecodeError(from, into, fmt.Errorf(format, args...))
+	return newDecodeError(kind, from, into, fmt.Errorf(format, args...))
 }
 
 // Unwrap returns the underlying error.
 func (e *DecodeError) Unwrap() error { return e.Err }
 
+// Is reports whether target is the kind of this error.
+func (e *DecodeError) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
 // Error returns the error message.
 func (e *DecodeError) Error() string {
-	return fmt.Sprintf("decodini: decode: failed at %s: %s", e.PathString(), e.Err)
+	msg := fmt.Sprintf("decodini: decode: failed at %s: %s", e.PathString(), e.Err)
+	return withSuggestions(msg, e.Suggestions)
 }
 
-// PathSTring returns a dot-separated string representation of the path.
-func (e *DecodeError) PathString() string {
-	path := e.From.Path()
-	if path == nil {
-		return "<root>"
+// SourceType returns the type of the source value, or nil if the source node
+// has no value (e.g. it is unmatched or nil).
+func (e *DecodeError) SourceType() reflect.Type {
+	if e.From == nil || !e.From.Value().IsValid() {
+		return nil
+	}
+	return e.From.Value().Type()
+}
+
+// TargetType returns the type of the target value, or nil if it is invalid.
+func (e *DecodeError) TargetType() reflect.Type {
+	if !e.Into.Value.IsValid() {
+		return nil
 	}
+	return e.Into.Value.Type()
+}
 
-	var sb strings.Builder
-	for i, item := range path {
-		sb.WriteString(fmt.Sprintf("%v", item))
-		if i < len(path)-1 {
-			sb.WriteString(".")
-		}
+// Path returns the path of the node at which decoding failed.
+func (e *DecodeError) Path() Path {
+	if e.From == nil {
+		return e.prefix
+	}
+	if len(e.prefix) == 0 {
+		return e.From.Path()
+	}
+	return append(slices.Clone(e.prefix), e.From.Path()...)
+}
+
+// PathString returns a dot-separated string representation of the path.
+func (e *DecodeError) PathString() string {
+	path := e.Path()
+	if len(path) == 0 {
+		return "<root>"
 	}
-	return sb.String()
+	return path.String()
 }
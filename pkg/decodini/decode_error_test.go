@@ -0,0 +1,118 @@
+package decodini
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeError_UnmatchedField(t *testing.T) {
+	type toStruct struct {
+		A string `decodini:"a"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{})
+
+	_, err := Decode[toStruct](nil, tr)
+	a.ErrorIs(err, ErrUnmatchedField)
+	a.NotErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Nil(decErr.SourceType())
+		a.Equal(reflect.TypeOf(toStruct{}), decErr.TargetType())
+	}
+}
+
+func TestDecodeError_TypeMismatch(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{"a": "foo"})
+
+	_, err := Decode[map[string]int](nil, tr)
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal("a", decErr.PathString())
+		a.Equal(reflect.TypeOf(""), decErr.SourceType())
+		a.Equal(reflect.TypeOf(0), decErr.TargetType())
+	}
+}
+
+func TestDecodeError_Overflow(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := Decode[int8](nil, Encode(nil, 300))
+	a.ErrorIs(err, ErrOverflow)
+
+	_, err = Decode[uint](nil, Encode(nil, -1))
+	a.ErrorIs(err, ErrOverflow)
+
+	to, err := Decode[int8](nil, Encode(nil, 100))
+	a.NoError(err)
+	a.Equal(int8(100), to)
+}
+
+func TestDecodeError_UnsupportedKind(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := Decode[[2]int](nil, Encode(nil, []int{1, 2}))
+	a.ErrorIs(err, ErrUnsupportedKind)
+}
+
+func TestDecodeError_Unsettable(t *testing.T) {
+	a := assert.New(t)
+
+	var to string
+	err := DecodeInto(nil, Encode(nil, "foo"), to)
+	a.ErrorIs(err, ErrUnsettable)
+}
+
+func TestDecodeError_CustomDecoderHasNoKind(t *testing.T) {
+	a := assert.New(t)
+
+	custom := errors.New("custom")
+	dec := &Decoding{
+		Decoder: func(*Tree, DecodeTarget) Decoder {
+			return func(*Tree, DecodeTarget) error { return custom }
+		},
+	}
+
+	_, err := Decode[string](dec, Encode(nil, "foo"))
+	a.ErrorIs(err, custom)
+	a.NotErrorIs(err, ErrTypeMismatch)
+}
+
+func TestNewDecodeError_PrefixPath(t *testing.T) {
+	a := assert.New(t)
+
+	err := NewDecodeError(ErrOverflow, Path{Field("b")}, errors.New("300 overflows int8"))
+	prefixed := PrefixPath(err, Field("a"), Index(1))
+
+	a.ErrorIs(prefixed, ErrOverflow)
+	a.Equal("decodini: decode: failed at a.1.b: 300 overflows int8", prefixed.Error())
+
+	var decErr *DecodeError
+	if a.ErrorAs(prefixed, &decErr) {
+		a.Equal(Path{Field("a"), Index(1), Field("b")}, decErr.Path())
+	}
+	a.Equal(Path{Field("b")}, err.Path(), "the original error is not modified")
+}
+
+func TestPrefixPath_TreeError(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{"a": "foo"})
+	_, err := Decode[map[string]int](nil, tr)
+
+	var decErr *DecodeError
+	if a.ErrorAs(PrefixPath(err, Field("outer")), &decErr) {
+		a.Equal(Path{Field("outer"), Key{Value: "a"}}, decErr.Path())
+	}
+	a.Equal(errors.New("foo"), PrefixPath(errors.New("foo"), Field("outer")))
+}
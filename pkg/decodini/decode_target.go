@@ -1,12 +1,111 @@
 package decodini
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+)
 
 type DecodeTarget struct {
 	Name  any
 	Value reflect.Value
 
-	structField *reflect.StructField
+	// field is the plan of the struct field the target is the value of, or
+	// nil.
+	field *fieldPlan
+
+	// inline is true if the target is an embedded struct that is decoded from
+	// the same node as its parent.
+	inline bool
+
+	// keyBy is the path of the field that is filled from the map key the
+	// target is decoded from, see Decoding.keyBy. The field does not need a
+	// counterpart in the source tree.
+	keyBy []string
+
+	// depth is the nesting depth of the target, starting at zero for the
+	// root.
+	depth int
+
+	// state is shared by all targets of a single decoding run.
+	state *decodeState
+}
+
+// decodeState holds the mutable state of a single decoding run.
+type decodeState struct {
+	ctx   context.Context
+	nodes int
+
+	// sem limits the number of additional goroutines decoding elements
+	// concurrently. It is nil if parallel decoding is disabled.
+	sem chan struct{}
+
+	// refs holds the pointers that nodes referenced by back-references were
+	// decoded into. It is only populated for trees encoded with CycleRef.
+	refs map[*Tree]reflect.Value
+
+	// shared maps the memory that nodes refer to, see Tree.ref, to the
+	// pointer, map or slice it was decoded into. It is only populated by
+	// Clone, which keeps memory shared within the source shared within the
+	// copy.
+	shared map[nodeRef]reflect.Value
+
+	// forked is nil unless the state belongs to an element decoded
+	// concurrently with its siblings, see decodeState.fork.
+	forked *forkState
+}
+
+// forkState holds the state of an element decoded concurrently with its
+// siblings.
+type forkState struct {
+	// parent is the state of the run this state was forked from.
+	parent *decodeState
+
+	// warnings collects the warnings of the element, which are reported when
+	// it is committed.
+	warnings []Warning
+}
+
+// setRef records that node was decoded into the pointer ptr.
+func (s *decodeState) setRef(node *Tree, ptr reflect.Value) {
+	if s.refs == nil {
+		s.refs = make(map[*Tree]reflect.Value)
+	}
+	s.refs[node] = ptr
+}
+
+// ref returns the pointer node was decoded into, or the zero Value if it is
+// unknown.
+func (s *decodeState) ref(node *Tree) reflect.Value {
+	for s != nil {
+		if ptr, ok := s.refs[node]; ok {
+			return ptr
+		}
+		if s.forked == nil {
+			break
+		}
+		s = s.forked.parent
+	}
+	return reflect.Value{}
+}
+
+// sub returns a target for the child value val with the given name, sharing
+// the state of d.
+func (d DecodeTarget) sub(name any, val reflect.Value) DecodeTarget {
+	return DecodeTarget{
+		Name:  name,
+		Value: val,
+		depth: d.depth + 1,
+		state: d.state,
+	}
+}
+
+// Context returns the context of the decoding run this target belongs to. It
+// is never nil.
+func (d DecodeTarget) Context() context.Context {
+	if d.state == nil || d.state.ctx == nil {
+		return context.Background()
+	}
+	return d.state.ctx
 }
 
 func (d DecodeTarget) IsPrimitive() bool {
@@ -14,12 +113,12 @@ func (d DecodeTarget) IsPrimitive() bool {
 }
 
 func (d DecodeTarget) IsStructField() bool {
-	return d.structField != nil
+	return d.field != nil
 }
 
 func (d DecodeTarget) StructField() reflect.StructField {
 	if !d.IsStructField() {
 		panic("decodini: decode target is not a struct field")
 	}
-	return *d.structField
+	return d.field.field
 }
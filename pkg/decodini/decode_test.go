@@ -127,6 +127,21 @@ func TestDecode_ShallowMap_to_ShallowMap(t *testing.T) {
 	a.NoError(err)
 }
 
+func TestDecode_NestedMap_to_MapOfInterfaces(t *testing.T) {
+	a := assert.New(t)
+
+	expected := map[string]any{
+		"a": map[string]any{
+			"b": []any{1, "foo"},
+		},
+	}
+	tr := Encode(nil, expected)
+
+	actual, err := Decode[map[string]any](nil, tr)
+	a.NoError(err)
+	a.Equal(expected, actual)
+}
+
 func TestDecode_ShallowMap_to_ShallowSlice(t *testing.T) {
 	a := assert.New(t)
 
@@ -645,3 +660,54 @@ func TestDecode_ASCIIOnly_ByteSlice_to_String(t *testing.T) {
 func ptr[T any](value T) *T {
 	return &value
 }
+
+func TestDecode_Cycle_Error(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := Decode[cyclicNode](nil, Encode(nil, cyclicList()))
+	a.ErrorIs(err, ErrCycle)
+	a.EqualError(err, "decodini: decode: failed at Next.Prev: cycle back to <root>")
+}
+
+func TestDecode_Cycle_Nil(t *testing.T) {
+	a := assert.New(t)
+
+	enc := &Encoding{Cycles: CycleNil}
+	to, err := Decode[cyclicNode](nil, Encode(enc, cyclicList()))
+	a.NoError(err)
+	a.Equal(1, to.Value)
+	if a.NotNil(to.Next) {
+		a.Equal(2, to.Next.Value)
+		a.Nil(to.Next.Prev)
+	}
+}
+
+func TestDecode_Cycle_Ref(t *testing.T) {
+	a := assert.New(t)
+
+	enc := &Encoding{Cycles: CycleRef}
+	from := cyclicList()
+
+	to, err := Decode[*cyclicNode](nil, Encode(enc, from))
+	a.NoError(err)
+	a.NotSame(from, to)
+	if a.NotNil(to.Next) {
+		a.Same(to, to.Next.Prev)
+	}
+
+	_, err = Decode[map[string]any](nil, Encode(enc, from))
+	a.ErrorIs(err, ErrCycle)
+}
+
+func TestDecode_SortedMap_to_Slice(t *testing.T) {
+	a := assert.New(t)
+
+	enc := &Encoding{SortMapKeys: true}
+	from := map[string]int{"c": 3, "a": 1, "b": 2, "d": 4}
+
+	for range 10 {
+		to, err := Decode[[]int](nil, Encode(enc, from))
+		a.NoError(err)
+		a.Equal([]int{1, 2, 3, 4}, to)
+	}
+}
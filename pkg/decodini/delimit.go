@@ -0,0 +1,277 @@
+package decodini
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Delimiting configures how delimited strings such as "a,b,c" are split into
+// slices and joined from them. A slice struct field is delimited if it has the
+// sep tag option, e.g. `decodini:"tags,sep=','"`, or when decoding with
+// Decoding.Separator set.
+type Delimiting struct {
+	// Quote encloses pieces that contain the separator, e.g. '"'. Quotes are
+	// removed when splitting and added when joining pieces that contain the
+	// separator or the quote. Without Escape, quotes within quoted pieces are
+	// doubled, like in CSV. Zero disables quoting.
+	Quote rune
+
+	// Escape makes the following character literal, e.g. '\\'. When joining,
+	// the separator, quote and escape characters within pieces are escaped.
+	// Zero disables escaping.
+	Escape rune
+
+	// TrimSpace removes white space around the pieces when splitting. Quoted
+	// and escaped white space is kept.
+	TrimSpace bool
+}
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// split splits s at every separator sep that is neither quoted nor escaped.
+// The empty string has no pieces.
+func (d Delimiting) split(s, sep string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var (
+		pieces []string
+		piece  strings.Builder
+		quoted bool
+
+		// piece[lo:hi] spans the quoted and escaped characters, which are
+		// not trimmed. lo is -1 if there are none.
+		lo, hi = -1, -1
+	)
+	keep := func(from int) {
+		if lo < 0 {
+			lo = from
+		}
+		hi = piece.Len()
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case d.Escape != 0 && r == d.Escape && i+size < len(s):
+			next, nextSize := utf8.DecodeRuneInString(s[i+size:])
+			from := piece.Len()
+			piece.WriteRune(next)
+			keep(from)
+			i += size + nextSize
+			continue
+		case d.Quote != 0 && r == d.Quote:
+			if quoted && d.Escape == 0 && strings.HasPrefix(s[i+size:], string(r)) {
+				// A doubled quote within a quoted piece is literal.
+				piece.WriteRune(r)
+				i += 2 * size
+				continue
+			}
+			quoted = !quoted
+			keep(piece.Len())
+		case !quoted && strings.HasPrefix(s[i:], sep):
+			pieces = append(pieces, d.trim(piece.String(), lo, hi))
+			piece.Reset()
+			lo, hi = -1, -1
+			i += len(sep)
+			continue
+		default:
+			piece.WriteRune(r)
+		}
+		i += size
+	}
+	if quoted {
+		return nil, errUnterminatedQuote
+	}
+	return append(pieces, d.trim(piece.String(), lo, hi)), nil
+}
+
+// trim removes the white space around piece if TrimSpace is set, keeping the
+// quoted or escaped range piece[lo:hi], if lo is not -1.
+func (d Delimiting) trim(piece string, lo, hi int) string {
+	switch {
+	case !d.TrimSpace:
+		return piece
+	case lo < 0:
+		return strings.TrimSpace(piece)
+	}
+	return strings.TrimLeftFunc(piece[:lo], unicode.IsSpace) +
+		piece[lo:hi] +
+		strings.TrimRightFunc(piece[hi:], unicode.IsSpace)
+}
+
+// join joins the pieces with the separator sep, escaping or quoting them so
+// that split restores them.
+func (d Delimiting) join(pieces []string, sep string) string {
+	var sb strings.Builder
+	for i, piece := range pieces {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+
+		// White space around the piece is protected from TrimSpace.
+		start, end := 0, len(piece)
+		if d.TrimSpace {
+			start = len(piece) - len(strings.TrimLeftFunc(piece, unicode.IsSpace))
+			end = max(start, len(strings.TrimRightFunc(piece, unicode.IsSpace)))
+		}
+
+		switch {
+		case d.Escape != 0:
+			for j, r := range piece {
+				if r == d.Escape || r == d.Quote || strings.HasPrefix(sep, string(r)) ||
+					j < start || j >= end {
+					sb.WriteRune(d.Escape)
+				}
+				sb.WriteRune(r)
+			}
+		case d.Quote != 0 && (strings.Contains(piece, sep) ||
+			strings.ContainsRune(piece, d.Quote) ||
+			start > 0 || end < len(piece)):
+			quote := string(d.Quote)
+			sb.WriteString(quote)
+			sb.WriteString(strings.ReplaceAll(piece, quote, quote+quote))
+			sb.WriteString(quote)
+		default:
+			sb.WriteString(piece)
+		}
+	}
+	return sb.String()
+}
+
+// separator returns the separator of the delimited strings decoded into the
+// slice target. The sep tag option of its struct field takes precedence over
+// Decoding.Separator, and disables splitting if empty. Decoding.Separator does
+// not apply to byte and rune slices, which strings are decoded into as text.
+func (dec *Decoding) separator(target DecodeTarget) (string, bool) {
+	if sep, ok := target.field.option("sep"); ok {
+		return sep, sep != ""
+	}
+	if isTextSlice(target.Value.Type()) {
+		return "", false
+	}
+	return dec.Separator, dec.Separator != ""
+}
+
+// isTextSlice reports whether typ is a byte or rune slice.
+func isTextSlice(typ reflect.Type) bool {
+	return isByteSequence(typ) || typ.Elem().Kind() == reflect.Int32
+}
+
+// intoSliceFromDelimited splits the string node at sep and decodes the pieces
+// into the elements of the slice target.
+func (dec *Decoding) intoSliceFromDelimited(
+	node *Tree,
+	target DecodeTarget,
+	sep string,
+) error {
+	pieces, err := dec.Delimiting.split(node.Value().String(), sep)
+	if err != nil {
+		return newDecodeErrorf(
+			ErrTypeMismatch,
+			node,
+			target,
+			"cannot split %q at %q: %w", node.Value().String(), sep, err,
+		)
+	}
+	if dec.MaxElements > 0 && len(pieces) > dec.MaxElements {
+		return newDecodeErrorf(
+			ErrLimitExceeded,
+			node,
+			target,
+			"%d pieces exceed maximum of %d elements",
+			len(pieces), dec.MaxElements,
+		)
+	}
+
+	// The pieces are decoded as the elements of a slice in place of node, so
+	// that errors refer to them by index.
+	list := encode(node.enc, node.parent, node.name, reflect.ValueOf(pieces))
+	list.field = node.field
+
+	typ := inferType(node, target)
+	dst := reflect.MakeSlice(typ, len(pieces), len(pieces))
+	for piece := range list.Children() {
+		sub := target.sub(piece.Name(), dst.Index(piece.Name().(int)))
+		if err := dec.intoPiece(piece, sub); err != nil {
+			return err
+		}
+	}
+	target.Value.Set(dst)
+	return nil
+}
+
+// hasDelimited reports whether typ is a struct type with fields that have the
+// sep option in the given tag.
+func hasDelimited(tag string, typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && structPlanOf(tag, typ).hasOption("sep")
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// intoPiece decodes the string piece into target. Pieces are parsed like map
+// keys, see convertScalar, and time.Duration values by time.ParseDuration.
+func (dec *Decoding) intoPiece(piece *Tree, target DecodeTarget) error {
+	typ := target.Value.Type()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Interface {
+		return dec.into(piece, target)
+	}
+
+	s := piece.Value().String()
+	var val reflect.Value
+	if typ == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return newDecodeErrorf(
+				ErrTypeMismatch,
+				piece,
+				target,
+				"cannot use %q as %s: %w", s, typ, err,
+			)
+		}
+		val = reflect.ValueOf(d)
+	} else {
+		var err *DecodeError
+		if val, err = convertScalar(s, typ, dec.FoldEnums, "piece"); err != nil {
+			err.From, err.Into = piece, target
+			return err
+		}
+	}
+	return dec.into(encode(piece.enc, piece.parent, piece.name, val), target)
+}
+
+// joinDelimited returns the elements of the slice or array val formatted and
+// joined with the separator sep.
+func (enc *Encoding) joinDelimited(val reflect.Value, sep string) string {
+	pieces := make([]string, val.Len())
+	for i := range pieces {
+		pieces[i] = formatPiece(val.Index(i))
+	}
+	return enc.Delimiting.join(pieces, sep)
+}
+
+// formatPiece formats the element val of a delimited slice. Nil pointers and
+// interfaces are formatted as the empty string.
+func formatPiece(val reflect.Value) string {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return ""
+		}
+		val = val.Elem()
+	}
+	if m, ok := val.Interface().(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(val.Interface())
+}
@@ -0,0 +1,149 @@
+package decodini
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type delimitedConfig struct {
+	Tags     []string        `decodini:"tags,sep=','"`
+	Ports    []int           `decodini:"ports,sep=' '"`
+	Timeouts []time.Duration `decodini:"timeouts,sep=';'"`
+}
+
+func TestDecode_Delimited(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"tags": "a,b,c", "ports": "80 443", "timeouts": "1s;1m"}
+	to, err := Decode[delimitedConfig](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(delimitedConfig{
+		Tags:     []string{"a", "b", "c"},
+		Ports:    []int{80, 443},
+		Timeouts: []time.Duration{time.Second, time.Minute},
+	}, to)
+
+	from["ports"] = "80 http"
+	_, err = Decode[delimitedConfig](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "ports"}, Index(1)}, decErr.Path())
+	}
+}
+
+func TestDecode_Delimited_DefaultSeparator(t *testing.T) {
+	a := assert.New(t)
+
+	dec := &Decoding{StructTag: "decodini", Separator: ","}
+
+	to, err := Decode[[]int](dec, Encode(nil, "1,2"))
+	a.NoError(err)
+	a.Equal([]int{1, 2}, to)
+
+	empty, err := Decode[[]int](dec, Encode(nil, ""))
+	a.NoError(err)
+	a.Empty(empty)
+
+	_, err = Decode[[]int](dec, Encode(nil, "1,x"))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.EqualError(
+		err,
+		`decodini: decode: failed at 1: cannot use piece "x" as int: invalid syntax`,
+	)
+
+	b, err := Decode[[]byte](dec, Encode(nil, "hello, world"))
+	a.NoError(err)
+	a.Equal([]byte("hello, world"), b)
+
+	r, err := Decode[[]rune](dec, Encode(nil, "a,b"))
+	a.NoError(err)
+	a.Equal([]rune("a,b"), r)
+
+	type raw struct {
+		Data []byte `decodini:"data,sep=''"`
+	}
+	data, err := Decode[raw](dec, Encode(nil, map[string]any{"data": "a,b"}))
+	a.NoError(err)
+	a.Equal([]byte("a,b"), data.Data)
+}
+
+func TestDecode_Delimited_QuotingAndEscaping(t *testing.T) {
+	a := assert.New(t)
+
+	dec := &Decoding{
+		StructTag:  "decodini",
+		Separator:  ",",
+		Delimiting: Delimiting{Quote: '"', Escape: '\\', TrimSpace: true},
+	}
+
+	to, err := Decode[[]string](dec, Encode(nil, `a, "b,c", d\,e`))
+	a.NoError(err)
+	a.Equal([]string{"a", "b,c", "d,e"}, to)
+
+	_, err = Decode[[]string](dec, Encode(nil, `a,"b`))
+	a.ErrorIs(err, ErrTypeMismatch)
+}
+
+func TestTransmute_Delimited_RoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	for _, d := range []Delimiting{
+		{Quote: '"'},
+		{Quote: '"', TrimSpace: true},
+		{Quote: '"', Escape: '\\', TrimSpace: true},
+		{Escape: '\\', TrimSpace: true},
+	} {
+		tr, err := NewTransmutation(WithSeparator(","), WithDelimiting(d))
+		a.NoError(err)
+
+		from := delimitedConfig{Tags: []string{`say "hi"`, " padded ", `"`, "a,b"}}
+		generic, err := Transmute[map[string]any](&tr, from)
+		a.NoError(err)
+
+		back, err := Transmute[delimitedConfig](&tr, generic)
+		if a.NoError(err, "%+v", d) {
+			a.Equal(from.Tags, back.Tags, "%+v: %s", d, generic["tags"])
+		}
+	}
+
+	dec := &Decoding{
+		StructTag:  "decodini",
+		Separator:  ",",
+		Delimiting: Delimiting{Quote: '"', TrimSpace: true},
+	}
+	to, err := Decode[[]string](dec, Encode(nil, `a , " b " ,"say ""hi"""`))
+	a.NoError(err)
+	a.Equal([]string{"a", " b ", `say "hi"`}, to)
+}
+
+func TestEncode_Delimited(t *testing.T) {
+	a := assert.New(t)
+
+	from := delimitedConfig{
+		Tags:     []string{"a", "b"},
+		Ports:    []int{80},
+		Timeouts: []time.Duration{time.Second},
+	}
+	to, err := Transmute[map[string]any](nil, from)
+	a.NoError(err)
+	a.Equal(map[string]any{"tags": "a,b", "ports": "80", "timeouts": "1s"}, to)
+
+	back, err := Transmute[delimitedConfig](nil, to)
+	a.NoError(err)
+	a.Equal(from, back)
+
+	enc := &Encoding{StructTag: "decodini", Delimiting: Delimiting{Escape: '\\'}}
+	tr := Encode(enc, delimitedConfig{Tags: []string{"a,b", `c\`}})
+	a.Equal(`a\,b,c\\`, tr.Child("tags").Value().Interface())
+
+	tm, err := NewTransmuter[delimitedConfig, map[string]any](nil)
+	a.NoError(err)
+
+	compiled, err := tm.Run(from)
+	a.NoError(err)
+	a.Equal(to, compiled)
+}
@@ -9,8 +9,58 @@ import (
 
 type Encoding struct {
 	StructTag string
+
+	// Cycles determines how pointers referring back to one of their ancestors
+	// are encoded. Defaults to CycleError.
+	Cycles CycleMode
+
+	// SortMapKeys makes map nodes yield their children ordered by key instead
+	// of in Go's random map order. Strings, numbers and booleans are ordered
+	// naturally, other keys using CompareMapKeys.
+	SortMapKeys bool
+
+	// CompareMapKeys compares map keys without a natural order if SortMapKeys
+	// is set. If nil, such keys are ordered by their fmt representation.
+	CompareMapKeys func(a, b any) int
+
+	// Sets makes set-shaped maps, i.e. maps with empty struct or boolean
+	// values, encode as slices of their keys in sorted order. Keys of boolean
+	// maps are only included if their value is true.
+	Sets bool
+
+	// Delimiting configures the quoting and escaping of slice struct fields
+	// with the sep tag option, which are encoded as delimited strings.
+	Delimiting Delimiting
+
+	// Entries makes maps encode as slices of entries with the given key and
+	// value fields, ordered by key, like the entries tag option does for
+	// struct fields. Set-shaped maps encoded by Sets are excluded. The zero
+	// value disables it.
+	Entries EntryNames
+
+	// Bytes is the encoding of byte slices and arrays. Unless it is BytesRaw,
+	// the default, they are encoded as strings.
+	Bytes ByteEncoding
 }
 
+// CycleMode determines how Encode handles pointer cycles, i.e. pointers, maps
+// or slices that refer to a value containing them.
+type CycleMode int
+
+const (
+	// CycleError encodes a cycle as a node without children that fails to
+	// decode with ErrCycle.
+	CycleError CycleMode = iota
+
+	// CycleNil cuts a cycle by encoding the back-reference as nil.
+	CycleNil
+
+	// CycleRef encodes a cycle as a back-reference node. Decoding it into a
+	// pointer sets the pointer to the value the referenced ancestor was
+	// decoded into, restoring the shared pointer.
+	CycleRef
+)
+
 var defaultEncoding = Encoding{
 	StructTag: "decodini",
 }
@@ -37,24 +87,187 @@ func encode(enc *Encoding, parent *Tree, name any, val reflect.Value) *Tree {
 		if val.IsNil() {
 			return &Tree{enc: enc, name: name, parent: parent, val: val, isNil: true}
 		}
-		return encode(enc, parent, name, val.Elem())
+		tr := encode(enc, parent, name, val.Elem())
+		if tr.ref().ptr == 0 && !tr.isNil && val.Type().Elem().Size() > 0 {
+			tr.setPointerRef(val)
+		}
+		return tr
 	case reflect.Interface:
 		if val.IsNil() {
 			return &Tree{enc: enc, name: name, parent: parent, val: val, isNil: true}
 		}
 		return encode(enc, parent, name, val.Elem())
+	case reflect.Struct:
+		tr := &Tree{enc: enc, name: name, parent: parent, val: val}
+		tr.tuple = isTupleType(val.Type())
+		return tr
+	case reflect.Map, reflect.Slice:
+		tr := &Tree{enc: enc, name: name, parent: parent, val: val}
+		tr.set = enc.Sets && isSetType(val.Type())
+		if val.Kind() == reflect.Map && !tr.set && enc.Entries != (EntryNames{}) {
+			tr.extra().entries = enc.Entries.withDefaults()
+		}
+		if ref := tr.ref(); ref.ptr != 0 {
+			tr.checkCycle(ref)
+		}
+		if enc.Bytes != BytesRaw && isByteSequence(val.Type()) {
+			tr.encodeBytes(enc.Bytes)
+		}
+		return tr
+	case reflect.Array:
+		tr := &Tree{enc: enc, name: name, parent: parent, val: val}
+		if enc.Bytes != BytesRaw && isByteSequence(val.Type()) {
+			tr.encodeBytes(enc.Bytes)
+		}
+		return tr
+	}
+	tr := &Tree{enc: enc, name: name, parent: parent, val: val}
+	if val.IsValid() {
+		if e := enumOf(val.Type()); e != nil {
+			tr.encodeEnum(e)
+		}
+	}
+	return tr
+}
+
+// nodeRef identifies the memory a node was reached through, i.e. the address
+// of a pointer, map or slice, which is used to detect cycles.
+type nodeRef struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// ref returns the reference of the memory this node was reached through.
+// Non-empty maps and slices are referenced by their contents, other values by
+// the pointer they were reached through, if any.
+func (t *Tree) ref() nodeRef {
+	switch val := t.val; {
+	case (val.Kind() == reflect.Map || val.Kind() == reflect.Slice) &&
+		!val.IsNil() && val.Len() > 0 && val.Type().Elem().Size() > 0:
+		return nodeRef{ptr: val.Pointer(), typ: val.Type(), len: val.Len()}
+	case t.viaPointer:
+		addr := val.Addr()
+		return nodeRef{ptr: addr.Pointer(), typ: addr.Type()}
+	case t.ext != nil:
+		return t.ext.ref
+	}
+	return nodeRef{}
+}
+
+// setPointerRef makes the non-nil pointer ptr the reference of t, which holds
+// the value ptr points to, and handles the cycle closed by t.
+func (t *Tree) setPointerRef(ptr reflect.Value) {
+	if t.val.CanAddr() && t.val.Addr().Type() == ptr.Type() &&
+		t.val.Addr().Pointer() == ptr.Pointer() {
+		// The reference is derived from the value, see ref.
+		t.viaPointer = true
+	} else {
+		t.extra().ref = nodeRef{ptr: ptr.Pointer(), typ: ptr.Type()}
+	}
+	t.checkCycle(t.ref())
+}
+
+// clearRef removes the reference of t, whose value no longer refers to the
+// memory it was reached through.
+func (t *Tree) clearRef() {
+	t.viaPointer = false
+	if t.ext != nil {
+		t.ext.ref = nodeRef{}
+	}
+}
+
+// checkCycle handles the cycle closed by t if an ancestor has the same
+// reference ref as t.
+func (t *Tree) checkCycle(ref nodeRef) {
+	for anc := t.parent; anc != nil; anc = anc.parent {
+		if anc.ref() != ref {
+			continue
+		}
+
+		if t.enc.Cycles == CycleNil {
+			t.val = reflect.Zero(ref.typ)
+			t.isNil = true
+			t.clearRef()
+			return
+		}
+		t.extra().cycle = anc
+		return
 	}
-	return &Tree{enc: enc, name: name, parent: parent, val: val}
 }
 
+// Tree is a node of the lazily encoded tree of a value. Fields that only few
+// nodes need are kept in treeExtra, so that nodes stay small.
 type Tree struct {
 	enc    *Encoding
 	name   any
 	parent *Tree
 	val    reflect.Value
 
-	isNil       bool
-	structField *reflect.StructField
+	// field is the plan of the struct field this node is the value of, or nil.
+	field *fieldPlan
+
+	// ext is nil unless the node has extra state, see extra.
+	ext *treeExtra
+
+	isNil bool
+
+	// tuple is true if the node is a struct in tuple mode, whose children
+	// are its field values by position.
+	tuple bool
+
+	// set is true if the node is a set-shaped map encoded as the slice of its
+	// keys, see Encoding.Sets.
+	set bool
+
+	// viaPointer is true if the node holds the addressable value of the
+	// pointer it was reached through, which is its reference.
+	viaPointer bool
+}
+
+// treeExtra holds the state of a node that only few nodes need.
+type treeExtra struct {
+	// ref is the reference of the node if it cannot be derived from its
+	// value, see Tree.ref.
+	ref nodeRef
+
+	// cycle is the ancestor the node refers back to if it closes a cycle.
+	cycle *Tree
+
+	// entries names the fields of the entries if the node is a map encoded as
+	// the slice of its entries, see Encoding.Entries.
+	entries EntryNames
+
+	// orig holds the original value of the node if the node holds a
+	// representation of it, i.e. the string of a byte slice or array (see
+	// Encoding.Bytes) or the name of an enum value (see RegisterEnum).
+	orig reflect.Value
+}
+
+// extra returns the extra state of t, allocating it if necessary.
+func (t *Tree) extra() *treeExtra {
+	if t.ext == nil {
+		t.ext = new(treeExtra)
+	}
+	return t.ext
+}
+
+// original returns the original value of this node if it holds a
+// representation of it, or the zero Value.
+func (t *Tree) original() reflect.Value {
+	if t.ext == nil {
+		return reflect.Value{}
+	}
+	return t.ext.orig
+}
+
+// entries returns the names of the entry fields if this node is a map encoded
+// as the slice of its entries, or the zero EntryNames.
+func (t *Tree) entries() EntryNames {
+	if t.ext == nil {
+		return EntryNames{}
+	}
+	return t.ext.entries
 }
 
 // Name returns the name of this node in the parent node. If this node is root
@@ -74,24 +287,53 @@ func (t *Tree) Value() reflect.Value {
 	return t.val
 }
 
+// source returns the original value of this node, which differs from Value if
+// the node holds a representation of it.
+func (t *Tree) source() reflect.Value {
+	if orig := t.original(); orig.IsValid() {
+		return orig
+	}
+	return t.val
+}
+
 // SetValue updates the value of this node to the given val. The original value
 // is not further used.
 func (t *Tree) SetValue(val reflect.Value) {
 	t.val = val
 	t.isNil = isNil(val)
+	t.viaPointer = false
 }
 
 func (t *Tree) IsPrimitive() bool {
 	return isPrimitive(t.val.Kind())
 }
 
-// Path returns the path from the root to this node. The first element is the
-// name of
-func (t *Tree) Path() (path []any) {
-	if t.name == nil || t.parent == nil {
+// Path returns the path from the root to this node. The path of the root node
+// is empty.
+func (t *Tree) Path() Path {
+	if t.parent == nil {
 		return nil
 	}
-	return append(t.parent.Path(), t.name)
+	return append(t.parent.Path(), t.segment())
+}
+
+// segment returns the path segment addressing this node in its parent.
+func (t *Tree) segment() PathSegment {
+	if name, ok := t.name.(string); ok && t.field != nil {
+		return Field(name)
+	}
+	switch t.parent.kind() {
+	case reflect.Slice, reflect.Array:
+		if i, ok := t.name.(int); ok {
+			return Index(i)
+		}
+	case reflect.Map:
+		return Key{Value: t.name}
+	}
+	if name, ok := t.name.(string); ok {
+		return Field(name)
+	}
+	return Key{Value: t.name}
 }
 
 // IsNil returns true if this node's value is nil.
@@ -100,14 +342,71 @@ func (t *Tree) IsNil() bool {
 }
 
 func (t *Tree) IsStructField() bool {
-	return t.structField != nil
+	return t.field != nil
+}
+
+// IsTuple reports whether this node is a struct in tuple mode. Its children
+// are named by their position, like the elements of a slice. See Tuple.
+func (t *Tree) IsTuple() bool {
+	return t.tuple
+}
+
+// IsSet reports whether this node is a set-shaped map whose children are its
+// keys, like the elements of a slice. See Encoding.Sets.
+func (t *Tree) IsSet() bool {
+	return t.set
+}
+
+// kind returns the kind of the value of this node, treating tuples, sets and
+// entries as slices.
+func (t *Tree) kind() reflect.Kind {
+	if t.tuple || t.set || t.entries() != (EntryNames{}) {
+		return reflect.Slice
+	}
+	return t.val.Kind()
+}
+
+// setStructField marks this node as the value of the struct field planned by
+// field.
+func (t *Tree) setStructField(field *fieldPlan) {
+	t.field = field
+	if t.val.Kind() == reflect.Struct && field.opts.Has("tuple") {
+		t.tuple = true
+	}
+	if isByteSequence(t.source().Type()) {
+		if e, ok := byteEncodingOf(field, t.enc.Bytes); ok {
+			t.encodeBytes(e)
+		}
+	}
+	if t.val.Kind() == reflect.Map {
+		if names, ok := entryNamesOf(field); ok {
+			t.extra().entries, t.set = names, false
+		}
+	}
+	if t.Cycle() == nil && (t.val.Kind() == reflect.Slice || t.val.Kind() == reflect.Array) {
+		// Delimited slices are leaves holding the joined string.
+		if sep, _ := field.option("sep"); sep != "" {
+			t.val = reflect.ValueOf(t.enc.joinDelimited(t.val, sep))
+			t.set = false
+			t.clearRef()
+		}
+	}
 }
 
 func (t *Tree) StructField() reflect.StructField {
 	if !t.IsStructField() {
 		panic("decodini: node is not a struct field")
 	}
-	return *t.structField
+	return t.field.field
+}
+
+// Cycle returns the ancestor this node refers back to if the node closes a
+// pointer cycle, or nil otherwise. Nodes closing a cycle have no children.
+func (t *Tree) Cycle() *Tree {
+	if t.ext == nil {
+		return nil
+	}
+	return t.ext.cycle
 }
 
 // DepthFirst returns a sequence of the tree nodes in depth-first order.
@@ -154,6 +453,18 @@ func (t *Tree) BreadthFirst() iter.Seq[*Tree] {
 
 // NumChildren returns the number of children of this node.
 func (t *Tree) NumChildren() uint {
+	if t.Cycle() != nil {
+		return 0
+	}
+	if t.tuple {
+		return uint(len(structPlanOf(t.enc.StructTag, t.val.Type()).tuple))
+	}
+	if t.set {
+		return uint(len(setKeys(t.enc, t.val)))
+	}
+	if t.entries() != (EntryNames{}) {
+		return uint(t.val.Len())
+	}
 	switch t.val.Kind() {
 	case reflect.Struct:
 		return numStructFields(t.enc.StructTag, t.val)
@@ -166,18 +477,44 @@ func (t *Tree) NumChildren() uint {
 
 // Child returns the child of this node with the given name.
 func (t *Tree) Child(name any) *Tree {
+	if t.Cycle() != nil {
+		return nil
+	}
+	if t.tuple {
+		pos, ok := name.(int)
+		if !ok {
+			return nil
+		}
+		return t.tupleChild(structPlanOf(t.enc.StructTag, t.val.Type()), pos)
+	}
+	if t.set {
+		i, ok := name.(int)
+		keys := setKeys(t.enc, t.val)
+		if !ok || i < 0 || i >= len(keys) {
+			return nil
+		}
+		return encode(t.enc, t, i, keys[i])
+	}
+	if t.entries() != (EntryNames{}) {
+		i, ok := name.(int)
+		keys := sortedMapKeys(t.enc, t.val)
+		if !ok || i < 0 || i >= len(keys) {
+			return nil
+		}
+		return t.entryChild(i, keys[i])
+	}
 	switch t.val.Kind() {
 	case reflect.Struct:
 		nameStr, ok := name.(string)
 		if !ok {
 			return nil
 		}
-		sf, vf := structFieldByName(t.enc.StructTag, t.val, nameStr)
+		field, vf := structFieldByName(t.enc.StructTag, t.val, nameStr)
 		if !vf.IsValid() {
 			return nil
 		}
 		tr := encode(t.enc, t, name, vf)
-		tr.structField = &sf
+		tr.setStructField(field)
 		return tr
 
 	case reflect.Slice, reflect.Array:
@@ -189,13 +526,15 @@ func (t *Tree) Child(name any) *Tree {
 		return tr
 
 	case reflect.Map:
-		nameVal := reflect.ValueOf(name)
-		for _, key := range t.val.MapKeys() {
-			if key.Equal(nameVal) {
-				return encode(t.enc, t, name, t.val.MapIndex(key))
-			}
+		key, ok := mapKeyOf(t.val.Type().Key(), name)
+		if !ok {
+			return nil
 		}
-		return nil
+		val := t.val.MapIndex(key)
+		if !val.IsValid() {
+			return nil
+		}
+		return encode(t.enc, t, name, val)
 
 	default:
 		return nil
@@ -205,6 +544,37 @@ func (t *Tree) Child(name any) *Tree {
 // Children returns a sequence of the children of this node, preserving their
 // order.
 func (t *Tree) Children() iter.Seq[*Tree] {
+	if t.Cycle() != nil {
+		return func(yield func(*Tree) bool) {}
+	}
+	if t.tuple {
+		return func(yield func(*Tree) bool) {
+			plan := structPlanOf(t.enc.StructTag, t.val.Type())
+			for pos := range plan.tuple {
+				if !yield(t.tupleChild(plan, pos)) {
+					return
+				}
+			}
+		}
+	}
+	if t.set {
+		return func(yield func(*Tree) bool) {
+			for i, key := range setKeys(t.enc, t.val) {
+				if !yield(encode(t.enc, t, i, key)) {
+					return
+				}
+			}
+		}
+	}
+	if t.entries() != (EntryNames{}) {
+		return func(yield func(*Tree) bool) {
+			for i, key := range sortedMapKeys(t.enc, t.val) {
+				if !yield(t.entryChild(i, key)) {
+					return
+				}
+			}
+		}
+	}
 	switch t.val.Kind() {
 	case reflect.Struct:
 		return func(yield func(*Tree) bool) {
@@ -224,9 +594,20 @@ func (t *Tree) Children() iter.Seq[*Tree] {
 		}
 
 	case reflect.Map:
+		if t.enc.SortMapKeys {
+			return func(yield func(*Tree) bool) {
+				for _, key := range sortedMapKeys(t.enc, t.val) {
+					tr := encode(t.enc, t, mapKeyName(key), t.val.MapIndex(key))
+					if !yield(tr) {
+						return
+					}
+				}
+			}
+		}
 		return func(yield func(*Tree) bool) {
-			for _, key := range t.val.MapKeys() {
-				tr := encode(t.enc, t, key.Interface(), t.val.MapIndex(key))
+			iter := t.val.MapRange()
+			for iter.Next() {
+				tr := encode(t.enc, t, mapKeyName(iter.Key()), iter.Value())
 				if !yield(tr) {
 					return
 				}
@@ -244,6 +625,14 @@ func (t *Tree) String() string {
 	sb.WriteString(fmt.Sprint(t.Name()))
 	sb.WriteString(" (")
 	sb.WriteString(t.val.Kind().String())
+	if cycle := t.Cycle(); cycle != nil {
+		path := cycle.Path().String()
+		if path == "" {
+			path = "<root>"
+		}
+		sb.WriteString(", cycle to ")
+		sb.WriteString(path)
+	}
 	sb.WriteString(")\n")
 	for child := range t.Children() {
 		sb.WriteString("  ")
@@ -252,6 +641,23 @@ func (t *Tree) String() string {
 	return sb.String()
 }
 
+// tupleChild returns the child of the tuple node at position pos. Positions
+// without a field or with a field behind a nil pointer are nil nodes.
+func (t *Tree) tupleChild(plan *structPlan, pos int) *Tree {
+	if pos < 0 || pos >= len(plan.tuple) {
+		return nil
+	}
+	if i := plan.tuple[pos]; i >= 0 {
+		field := &plan.flat[i]
+		if vf, ok := fieldByIndex(t.val, field.index); ok {
+			tr := encode(t.enc, t, pos, vf)
+			tr.setStructField(field)
+			return tr
+		}
+	}
+	return t.dummyChild(pos)
+}
+
 func (t *Tree) dummyChild(name any) *Tree {
 	return &Tree{
 		enc:    t.enc,
@@ -275,29 +681,16 @@ func yieldStructFields(
 		panic("decodini: cannot yield struct fields of non-struct")
 	}
 
-	typ := val.Type()
-	for i := range val.NumField() {
-		sf := typ.Field(i)
-		if !includeStructField(enc.StructTag, sf) {
-			continue
-		}
-		vf := val.Field(i)
-
-		if sf.Anonymous {
-			if !yieldStructFields(enc, parent, vf, yield) {
-				return false
-			}
+	plan := structPlanOf(enc.StructTag, val.Type())
+	for i := range plan.flat {
+		field := &plan.flat[i]
+		vf, ok := fieldByIndex(val, field.index)
+		if !ok {
 			continue
 		}
 
-		name := sf.Name
-		tagName, hasTag := sf.Tag.Lookup(enc.StructTag)
-		if hasTag {
-			name = tagName
-		}
-
-		tr := encode(enc, parent, name, vf)
-		tr.structField = &sf
+		tr := encode(enc, parent, field.name, vf)
+		tr.setStructField(field)
 
 		if !yield(tr) {
 			return false
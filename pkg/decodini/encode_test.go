@@ -208,6 +208,29 @@ func TestEncode_ShallowMap(t *testing.T) {
 	}
 }
 
+func TestEncode_MapChild_KeyTypes(t *testing.T) {
+	type myString string
+
+	a := assert.New(t)
+
+	named := Encode(nil, map[myString]int{"a": 1})
+	if child := named.Child("a"); a.NotNil(child) {
+		a.Equal(1, child.Value().Interface())
+	}
+
+	iface := Encode(nil, map[any]int{"a": 1, 2: 2})
+	if child := iface.Child("a"); a.NotNil(child) {
+		a.Equal(1, child.Value().Interface())
+	}
+	if child := iface.Child(2); a.NotNil(child) {
+		a.Equal(2, child.Value().Interface())
+	}
+
+	a.Nil(named.Child("b"))
+	a.Nil(named.Child(1))
+	a.Nil(iface.Child([]int{1}))
+}
+
 func TestEncode_NilPointerAndInterface(t *testing.T) {
 	a := assert.New(t)
 
@@ -417,13 +440,13 @@ func TestTree_DepthFirst(t *testing.T) {
 		a.Len(df, 3)
 
 		a.Equal(val, df[0].Value().Interface())
-		a.Equal([]any(nil), df[0].Path())
+		a.Equal(Path(nil), df[0].Path())
 
 		a.Equal(val.A, df[1].Value().Interface())
-		a.Equal([]any{"a"}, df[1].Path())
+		a.Equal(Path{Field("a")}, df[1].Path())
 
 		a.Equal(val.B, df[2].Value().Interface())
-		a.Equal([]any{"B"}, df[2].Path())
+		a.Equal(Path{Field("B")}, df[2].Path())
 	})
 
 	t.Run("Nested", func(t *testing.T) {
@@ -456,16 +479,16 @@ func TestTree_DepthFirst(t *testing.T) {
 		a.Len(df, 4)
 
 		a.Equal(val, df[0].Value().Interface())
-		a.Equal([]any(nil), df[0].Path())
+		a.Equal(Path(nil), df[0].Path())
 
 		a.Equal(val.Inner, df[1].Value().Interface())
-		a.Equal([]any{"Inner"}, df[1].Path())
+		a.Equal(Path{Field("Inner")}, df[1].Path())
 
 		a.Equal(val.Inner.A, df[2].Value().Interface())
-		a.Equal([]any{"Inner", "a"}, df[2].Path())
+		a.Equal(Path{Field("Inner"), Field("a")}, df[2].Path())
 
 		a.Equal(val.Inner.B, df[3].Value().Interface())
-		a.Equal([]any{"Inner", "B"}, df[3].Path())
+		a.Equal(Path{Field("Inner"), Field("B")}, df[3].Path())
 	})
 
 	t.Run("Backtracking", func(t *testing.T) {
@@ -501,19 +524,19 @@ func TestTree_DepthFirst(t *testing.T) {
 		a.Len(df, 5)
 
 		a.Equal(val, df[0].Value().Interface())
-		a.Equal([]any(nil), df[0].Path())
+		a.Equal(Path(nil), df[0].Path())
 
 		a.Equal(val.Inner, df[1].Value().Interface())
-		a.Equal([]any{"Inner"}, df[1].Path())
+		a.Equal(Path{Field("Inner")}, df[1].Path())
 
 		a.Equal(val.Inner.A, df[2].Value().Interface())
-		a.Equal([]any{"Inner", "a"}, df[2].Path())
+		a.Equal(Path{Field("Inner"), Field("a")}, df[2].Path())
 
 		a.Equal(val.Inner.B, df[3].Value().Interface())
-		a.Equal([]any{"Inner", "B"}, df[3].Path())
+		a.Equal(Path{Field("Inner"), Field("B")}, df[3].Path())
 
 		a.Equal(val.D, df[4].Value().Interface())
-		a.Equal([]any{"D"}, df[4].Path())
+		a.Equal(Path{Field("D")}, df[4].Path())
 	})
 
 	t.Run("Embedded", func(t *testing.T) {
@@ -546,13 +569,13 @@ func TestTree_DepthFirst(t *testing.T) {
 		a.Len(df, 3)
 
 		a.Equal(val, df[0].Value().Interface())
-		a.Equal([]any(nil), df[0].Path())
+		a.Equal(Path(nil), df[0].Path())
 
 		a.Equal(val.A, df[1].Value().Interface())
-		a.Equal([]any{"a"}, df[1].Path())
+		a.Equal(Path{Field("a")}, df[1].Path())
 
 		a.Equal(val.B, df[2].Value().Interface())
-		a.Equal([]any{"B"}, df[2].Path())
+		a.Equal(Path{Field("B")}, df[2].Path())
 	})
 }
 
@@ -599,13 +622,13 @@ func TestTree_BreadthFirst(t *testing.T) {
 		a.Len(bf, 3)
 
 		a.Equal(val, bf[0].Value().Interface())
-		a.Equal([]any(nil), bf[0].Path())
+		a.Equal(Path(nil), bf[0].Path())
 
 		a.Equal(val.A, bf[1].Value().Interface())
-		a.Equal([]any{"a"}, bf[1].Path())
+		a.Equal(Path{Field("a")}, bf[1].Path())
 
 		a.Equal(val.B, bf[2].Value().Interface())
-		a.Equal([]any{"B"}, bf[2].Path())
+		a.Equal(Path{Field("B")}, bf[2].Path())
 	})
 
 	t.Run("Nested", func(t *testing.T) {
@@ -638,16 +661,16 @@ func TestTree_BreadthFirst(t *testing.T) {
 		a.Len(bf, 4)
 
 		a.Equal(val, bf[0].Value().Interface())
-		a.Equal([]any(nil), bf[0].Path())
+		a.Equal(Path(nil), bf[0].Path())
 
 		a.Equal(val.Inner, bf[1].Value().Interface())
-		a.Equal([]any{"Inner"}, bf[1].Path())
+		a.Equal(Path{Field("Inner")}, bf[1].Path())
 
 		a.Equal(val.Inner.A, bf[2].Value().Interface())
-		a.Equal([]any{"Inner", "a"}, bf[2].Path())
+		a.Equal(Path{Field("Inner"), Field("a")}, bf[2].Path())
 
 		a.Equal(val.Inner.B, bf[3].Value().Interface())
-		a.Equal([]any{"Inner", "B"}, bf[3].Path())
+		a.Equal(Path{Field("Inner"), Field("B")}, bf[3].Path())
 	})
 
 	t.Run("Backtracking", func(t *testing.T) {
@@ -683,19 +706,19 @@ func TestTree_BreadthFirst(t *testing.T) {
 		a.Len(bf, 5)
 
 		a.Equal(val, bf[0].Value().Interface())
-		a.Equal([]any(nil), bf[0].Path())
+		a.Equal(Path(nil), bf[0].Path())
 
 		a.Equal(val.Inner, bf[1].Value().Interface())
-		a.Equal([]any{"Inner"}, bf[1].Path())
+		a.Equal(Path{Field("Inner")}, bf[1].Path())
 
 		a.Equal(val.D, bf[2].Value().Interface())
-		a.Equal([]any{"D"}, bf[2].Path())
+		a.Equal(Path{Field("D")}, bf[2].Path())
 
 		a.Equal(val.Inner.A, bf[3].Value().Interface())
-		a.Equal([]any{"Inner", "a"}, bf[3].Path())
+		a.Equal(Path{Field("Inner"), Field("a")}, bf[3].Path())
 
 		a.Equal(val.Inner.B, bf[4].Value().Interface())
-		a.Equal([]any{"Inner", "B"}, bf[4].Path())
+		a.Equal(Path{Field("Inner"), Field("B")}, bf[4].Path())
 	})
 
 	t.Run("Embedded", func(t *testing.T) {
@@ -728,12 +751,145 @@ func TestTree_BreadthFirst(t *testing.T) {
 		a.Len(bf, 3)
 
 		a.Equal(val, bf[0].Value().Interface())
-		a.Equal([]any(nil), bf[0].Path())
+		a.Equal(Path(nil), bf[0].Path())
 
 		a.Equal(val.A, bf[1].Value().Interface())
-		a.Equal([]any{"a"}, bf[1].Path())
+		a.Equal(Path{Field("a")}, bf[1].Path())
 
 		a.Equal(val.B, bf[2].Value().Interface())
-		a.Equal([]any{"B"}, bf[2].Path())
+		a.Equal(Path{Field("B")}, bf[2].Path())
 	})
 }
+
+type cyclicNode struct {
+	Value int
+	Prev  *cyclicNode
+	Next  *cyclicNode
+}
+
+// cyclicList returns a doubly-linked list of two nodes.
+func cyclicList() *cyclicNode {
+	first := &cyclicNode{Value: 1}
+	first.Next = &cyclicNode{Value: 2, Prev: first}
+	return first
+}
+
+func TestEncode_Cycle(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, cyclicList())
+	nodes := slices.Collect(tr.DepthFirst())
+	a.Len(nodes, 7)
+
+	prev := tr.Child("Next").Child("Prev")
+	a.Same(tr, prev.Cycle())
+	a.Zero(prev.NumChildren())
+	a.Nil(prev.Child("Value"))
+	a.Nil(tr.Cycle())
+
+	a.Contains(tr.String(), "- Prev (struct, cycle to <root>)")
+}
+
+func TestEncode_Cycle_Nil(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(&Encoding{Cycles: CycleNil}, cyclicList())
+	prev := tr.Child("Next").Child("Prev")
+	a.True(prev.IsNil())
+	a.Nil(prev.Cycle())
+}
+
+func TestEncode_Cycle_Map(t *testing.T) {
+	a := assert.New(t)
+
+	m := map[string]any{"a": 1}
+	m["self"] = m
+
+	tr := Encode(nil, m)
+	a.Same(tr, tr.Child("self").Cycle())
+}
+
+func TestEncode_Cycle_PointerToInterface(t *testing.T) {
+	type node struct {
+		Next *any
+	}
+
+	a := assert.New(t)
+
+	var v any
+	v = node{Next: &v}
+
+	tr := Encode(nil, &v)
+	a.Same(tr, tr.Child("Next").Cycle())
+}
+
+func TestEncode_SharedPointer_IsNoCycle(t *testing.T) {
+	type pair struct {
+		A, B *int
+	}
+
+	a := assert.New(t)
+
+	v := 42
+	tr := Encode(nil, pair{A: &v, B: &v})
+	a.Nil(tr.Child("A").Cycle())
+	a.Nil(tr.Child("B").Cycle())
+}
+
+func TestEncode_SortMapKeys(t *testing.T) {
+	a := assert.New(t)
+
+	enc := &Encoding{SortMapKeys: true}
+	names := func(val any) []any {
+		var names []any
+		for child := range Encode(enc, val).Children() {
+			names = append(names, child.Name())
+		}
+		return names
+	}
+
+	a.Equal(
+		[]any{"a", "b", "c", "d"},
+		names(map[string]int{"c": 3, "a": 1, "d": 4, "b": 2}),
+	)
+	a.Equal(
+		[]any{-2, 1, 10, 300},
+		names(map[int]bool{10: true, 300: true, -2: true, 1: true}),
+	)
+	a.Equal(
+		[]any{nil, false, true, 1, 2.5, uint8(3), "a", "b"},
+		names(map[any]int{"b": 0, 2.5: 0, true: 0, nil: 0, "a": 0, uint8(3): 0, 1: 0, false: 0}),
+	)
+}
+
+func TestEncode_SortMapKeys_Compare(t *testing.T) {
+	type point struct{ X, Y int }
+
+	a := assert.New(t)
+
+	enc := &Encoding{
+		SortMapKeys: true,
+		CompareMapKeys: func(a, b any) int {
+			return b.(point).X - a.(point).X
+		},
+	}
+
+	var names []any
+	tr := Encode(enc, map[point]int{{1, 0}: 1, {3, 0}: 3, {2, 0}: 2})
+	for child := range tr.Children() {
+		names = append(names, child.Name())
+	}
+	a.Equal([]any{point{3, 0}, point{2, 0}, point{1, 0}}, names)
+}
+
+func TestEncode_SortMapKeys_String(t *testing.T) {
+	a := assert.New(t)
+
+	val := map[string]any{"b": 2, "a": 1, "c": map[string]int{"y": 1, "x": 2}}
+	tr := Encode(&Encoding{SortMapKeys: true}, val)
+
+	a.Equal(
+		"- <nil> (map)\n  - a (int)\n  - b (int)\n  - c (map)\n  - x (int)\n  - y (int)\n",
+		tr.String(),
+	)
+}
@@ -0,0 +1,219 @@
+package decodini
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EntryNames names the key and value fields of the entries that maps are
+// converted from and into, e.g. the fields Name and Value of
+// `[{"name": "HOME", "value": "/root"}]`. Empty names default to Key and
+// Value, unless both are empty.
+type EntryNames struct {
+	Key   string
+	Value string
+}
+
+// Default names of the key and value fields of entries.
+const (
+	defaultEntryKey   = "Key"
+	defaultEntryValue = "Value"
+)
+
+// withDefaults returns the names with empty names replaced by their default,
+// or the zero EntryNames if both are empty.
+func (n EntryNames) withDefaults() EntryNames {
+	if n == (EntryNames{}) {
+		return n
+	}
+	if n.Key == "" {
+		n.Key = defaultEntryKey
+	}
+	if n.Value == "" {
+		n.Value = defaultEntryValue
+	}
+	return n
+}
+
+// entryNamesOf returns the names set by the entries tag option of field. The
+// option either has no value, e.g. `decodini:"env,entries"`, to use the
+// fields Key and Value, or names the fields separated by a colon, e.g.
+// `decodini:"env,entries=name:value"`.
+func entryNamesOf(field *fieldPlan) (EntryNames, bool) {
+	raw, ok := field.option("entries")
+	if !ok {
+		return EntryNames{}, false
+	}
+	key, value, _ := strings.Cut(raw, ":")
+	return EntryNames{Key: cmp.Or(key, defaultEntryKey), Value: value}.withDefaults(), true
+}
+
+// entries returns the names of the key and value fields of the entries the map
+// or slice target is converted from or into. The entries tag option of its
+// struct field takes precedence over Decoding.Entries, which does not apply to
+// struct fields with the keyby option and slices of other than structs.
+func (dec *Decoding) entries(target DecodeTarget) (EntryNames, bool) {
+	if names, ok := entryNamesOf(target.field); ok {
+		return names, true
+	}
+	if _, ok := dec.keyBy(target); ok {
+		return EntryNames{}, false
+	}
+	if dec.Entries == (EntryNames{}) {
+		return EntryNames{}, false
+	}
+	if typ := target.Value.Type(); typ.Kind() == reflect.Slice && !isEntryType(typ.Elem()) {
+		return EntryNames{}, false
+	}
+	return dec.Entries.withDefaults(), true
+}
+
+// isEntryType reports whether typ is a struct type or a pointer to one, which
+// can hold an entry.
+func isEntryType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
+
+// hasEntries reports whether typ is a struct type with fields that have the
+// entries option in the given tag.
+func hasEntries(tag string, typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && structPlanOf(tag, typ).hasOption("entries")
+}
+
+// intoMapFromEntries decodes a slice or array of entries, i.e. of structs or
+// maps with a key and value field, into the map target like the map of the
+// keys to the values. Entries with the same key are rejected.
+func (dec *Decoding) intoMapFromEntries(
+	node *Tree,
+	target DecodeTarget,
+	names EntryNames,
+) error {
+	n := int(node.NumChildren())
+	keyType := target.Value.Type().Key()
+	keys := make([]any, 0, n)
+	keyNodes := make([]*Tree, 0, n)
+	values := make([]*Tree, 0, n)
+	seen := make(map[any]*Tree, n)
+
+	for entry := range node.Children() {
+		keyNode, err := entryField(entry, target, names.Key)
+		if err != nil {
+			return err
+		}
+		valueNode, err := entryField(entry, target, names.Value)
+		if err != nil {
+			return err
+		}
+
+		var raw any
+		if !keyNode.IsNil() {
+			raw = keyNode.Value().Interface()
+		}
+		// Duplicates are detected on the converted keys, which may merge
+		// distinct source keys such as "1" and 1.
+		conv, convErr := convertKey(raw, keyType, dec.FoldEnums)
+		if convErr != nil {
+			convErr.From, convErr.Into = keyNode, target
+			return convErr
+		}
+		if !conv.Comparable() {
+			return newDecodeErrorf(
+				ErrTypeMismatch,
+				keyNode,
+				target,
+				"cannot use key of type %T as map key", raw,
+			)
+		}
+		key := conv.Interface()
+		if prev, ok := seen[key]; ok {
+			return newDecodeErrorf(
+				ErrDuplicateKey,
+				keyNode,
+				target,
+				"key %v of entry %v is already used by entry %v",
+				key, entry.Name(), prev.Name(),
+			)
+		}
+		seen[key] = entry
+
+		keys = append(keys, key)
+		keyNodes = append(keyNodes, keyNode)
+		values = append(values, valueNode)
+	}
+
+	return dec.intoMapFromChildren(
+		node,
+		target,
+		slices.Values(values),
+		n,
+		nil,
+		func(i int, _ *Tree) (any, *Tree) { return keys[i], keyNodes[i] },
+	)
+}
+
+// entryField returns the child of the entry node with the given name.
+func entryField(node *Tree, target DecodeTarget, name string) (*Tree, error) {
+	if child := node.Child(name); child != nil {
+		return child, nil
+	}
+	return nil, newDecodeErrorf(
+		ErrUnmatchedField,
+		node.dummyChild(name),
+		target,
+		"entry field %s is missing in source tree", name,
+	)
+}
+
+// intoSliceFromEntries decodes the children of a map or struct node into the
+// slice target like its values, storing the key and value of every child into
+// the key and value field of an entry struct.
+func (dec *Decoding) intoSliceFromEntries(
+	node *Tree,
+	target DecodeTarget,
+	names EntryNames,
+) error {
+	return dec.intoSliceFromChildren(
+		node,
+		target,
+		func(from *Tree, entry DecodeTarget) error {
+			keyVal, err := keyField(dec.StructTag, entry.Value, []string{names.Key}, true)
+			if err == nil {
+				var key reflect.Value
+				key, err = convertKey(from.Name(), keyVal.Type(), dec.FoldEnums)
+				if err == nil {
+					keyVal.Set(key)
+				}
+			}
+			if err != nil {
+				err.From, err.Into = from, entry
+				return err
+			}
+
+			val, err := keyField(dec.StructTag, entry.Value, []string{names.Value}, true)
+			if err != nil {
+				err.From, err.Into = from, entry
+				return err
+			}
+			return dec.into(from, entry.sub(names.Value, val))
+		},
+	)
+}
+
+// entryChild returns the node of the entry at position i of the map node
+// encoded as entries, holding key and its value.
+func (t *Tree) entryChild(i int, key reflect.Value) *Tree {
+	entry := map[string]any{
+		t.entries().Key:   key.Interface(),
+		t.entries().Value: t.val.MapIndex(key).Interface(),
+	}
+	tr := encode(t.enc, t, i, reflect.ValueOf(entry))
+	if tr.ext != nil {
+		tr.ext.entries = EntryNames{}
+	}
+	return tr
+}
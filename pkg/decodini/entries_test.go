@@ -0,0 +1,168 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type envEntry struct {
+	Name  string `decodini:"name"`
+	Value string `decodini:"value"`
+}
+
+func TestDecode_Map_to_Entries(t *testing.T) {
+	type entry struct {
+		Key   int
+		Value string
+	}
+	type holder struct {
+		Ports []entry `decodini:"ports,entries"`
+	}
+
+	a := assert.New(t)
+
+	from := map[string]any{"ports": map[string]string{"80": "http"}}
+	to, err := Decode[holder](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal([]entry{{Key: 80, Value: "http"}}, to.Ports)
+
+	from = map[string]any{"ports": map[string]string{"x": "http"}}
+	_, err = Decode[holder](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "ports"}, Key{Value: "x"}}, decErr.Path())
+	}
+}
+
+func TestDecode_Entries_to_Map(t *testing.T) {
+	type holder struct {
+		Env map[string]string `decodini:"env,entries=name:value"`
+	}
+
+	a := assert.New(t)
+
+	from := map[string]any{
+		"env": []any{
+			map[string]any{"name": "HOME", "value": "/root"},
+			envEntry{Name: "USER", Value: "root"},
+		},
+	}
+	to, err := Decode[holder](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(map[string]string{"HOME": "/root", "USER": "root"}, to.Env)
+
+	from["env"] = []any{map[string]any{"name": "HOME"}}
+	_, err = Decode[holder](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrUnmatchedField)
+	a.EqualError(
+		err,
+		"decodini: decode: failed at env.0.value: entry field value is missing in source tree",
+	)
+
+	from["env"] = []envEntry{{Name: "HOME", Value: "/root"}, {Name: "HOME", Value: "/"}}
+	_, err = Decode[holder](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrDuplicateKey)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "env"}, Index(1), Field("name")}, decErr.Path())
+	}
+
+	type ports struct {
+		Ports map[int]string `decodini:"ports,entries"`
+	}
+	_, err = Decode[ports](nil, Encode(nil, map[string]any{
+		"ports": []any{
+			map[string]any{"Key": "80", "Value": "http"},
+			map[string]any{"Key": 80, "Value": "www"},
+		},
+	}))
+	a.ErrorIs(err, ErrDuplicateKey)
+	a.ErrorContains(err, "key 80 of entry 1 is already used by entry 0")
+}
+
+func TestTransmuter_Entries_RoundTrip(t *testing.T) {
+	type entries struct {
+		Env []envEntry `decodini:"env,entries=name:value"`
+	}
+	type mapped struct {
+		Env map[string]string `decodini:"env,entries=name:value"`
+	}
+
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[entries, mapped](nil)
+	a.NoError(err)
+
+	to, err := tm.Run(entries{Env: []envEntry{{Name: "HOME", Value: "/root"}}})
+	a.NoError(err)
+	a.Equal(map[string]string{"HOME": "/root"}, to.Env)
+
+	back, err := Transmute[entries](nil, to)
+	a.NoError(err)
+	a.Equal([]envEntry{{Name: "HOME", Value: "/root"}}, back.Env)
+}
+
+func TestEncode_Entries(t *testing.T) {
+	type holder struct {
+		Env map[string]string `decodini:"env,entries=name:value"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, holder{Env: map[string]string{"USER": "root", "HOME": "/root"}})
+	env := tr.Child("env")
+	a.Equal(uint(2), env.NumChildren())
+	a.Equal("HOME", env.Child(0).Child("name").Value().Interface())
+	a.Equal("/root", env.Child(0).Child("value").Value().Interface())
+
+	generic, err := Decode[map[string]any](nil, tr)
+	a.NoError(err)
+	a.Equal(map[string]any{
+		"env": []map[string]any{
+			{"name": "HOME", "value": "/root"},
+			{"name": "USER", "value": "root"},
+		},
+	}, generic)
+
+	tr = Encode(&Encoding{Entries: EntryNames{Key: "name"}}, map[string]int{"b": 2, "a": 1})
+	a.Equal("a", tr.Child(0).Child("name").Value().Interface())
+	a.Equal(1, tr.Child(0).Child("Value").Value().Interface())
+}
+
+func TestTransmute_Entries_Root(t *testing.T) {
+	a := assert.New(t)
+
+	tr, err := NewTransmutation(WithEntries(EntryNames{Key: "name", Value: "value"}))
+	a.NoError(err)
+
+	env, err := Transmute[map[string]string](&tr, []envEntry{
+		{Name: "HOME", Value: "/root"},
+		{Name: "USER", Value: "root"},
+	})
+	a.NoError(err)
+	a.Equal(map[string]string{"HOME": "/root", "USER": "root"}, env)
+
+	back, err := Transmute[[]envEntry](&tr, env)
+	a.NoError(err)
+	a.Equal([]envEntry{{Name: "HOME", Value: "/root"}, {Name: "USER", Value: "root"}}, back)
+
+	tm, err := NewTransmuter[map[string]string, []envEntry](&tr)
+	a.NoError(err)
+
+	back, err = tm.Run(env)
+	a.NoError(err)
+	a.Equal([]envEntry{{Name: "HOME", Value: "/root"}, {Name: "USER", Value: "root"}}, back)
+
+	_, err = Transmute[map[string]string](&tr, []envEntry{
+		{Name: "HOME", Value: "/root"},
+		{Name: "HOME", Value: "/"},
+	})
+	a.ErrorIs(err, ErrDuplicateKey)
+
+	_, err = Transmute[[]int](&tr, map[string]int{"a": 1})
+	a.ErrorIs(err, ErrTypeMismatch)
+}
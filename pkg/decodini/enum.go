@@ -0,0 +1,271 @@
+package decodini
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EnumValue is the constraint of the types that can be registered as enums.
+type EnumValue interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// enum describes a registered enum type.
+type enum struct {
+	typ   reflect.Type
+	flags bool
+
+	// names holds the names ordered by value, then name.
+	names  []string
+	values map[string]uint64
+
+	// byValue maps the values to their first name in names.
+	byValue map[uint64]string
+}
+
+var enums sync.Map // map[reflect.Type]*enum
+
+// RegisterEnum registers the names of the values of the enum type T. Strings
+// are then decoded into T by name, and values of T are encoded as their name.
+// Values without name are encoded as numbers. Registering T again replaces its
+// names.
+//
+// RegisterEnum is meant to be called from init functions. Encodings and
+// decodings running concurrently may or may not see the registration.
+func RegisterEnum[T EnumValue](names map[string]T) {
+	registerEnum(names, false)
+}
+
+// RegisterEnumValues registers the enum type T with the given values, named by
+// their String method.
+func RegisterEnumValues[T interface {
+	EnumValue
+	fmt.Stringer
+}](values ...T) {
+	names := make(map[string]T, len(values))
+	for _, v := range values {
+		names[v.String()] = v
+	}
+	registerEnum(names, false)
+}
+
+// RegisterFlags registers the names of the bits of the bit-flag enum type T.
+// In addition to single names, T is then decoded from lists of names, which
+// are combined. Values of T are encoded as the list of the names of their
+// bits, ordered by value. Values with bits without name are encoded as
+// numbers.
+func RegisterFlags[T EnumValue](names map[string]T) {
+	registerEnum(names, true)
+}
+
+func registerEnum[T EnumValue](names map[string]T, flags bool) {
+	e := &enum{
+		typ:     reflect.TypeFor[T](),
+		flags:   flags,
+		names:   make([]string, 0, len(names)),
+		values:  make(map[string]uint64, len(names)),
+		byValue: make(map[uint64]string, len(names)),
+	}
+	for name, v := range names {
+		if name == "" {
+			panic("decodini: enum names must not be empty")
+		}
+		e.names = append(e.names, name)
+		e.values[name] = bitsOf(reflect.ValueOf(v))
+	}
+	slices.SortFunc(e.names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(e.values[a], e.values[b]), cmp.Compare(a, b))
+	})
+	for _, name := range e.names {
+		if _, ok := e.byValue[e.values[name]]; !ok {
+			e.byValue[e.values[name]] = name
+		}
+	}
+	enums.Store(e.typ, e)
+}
+
+// enumOf returns the registered enum of typ, or nil.
+func enumOf(typ reflect.Type) *enum {
+	if e, ok := enums.Load(typ); ok {
+		return e.(*enum)
+	}
+	return nil
+}
+
+// bitsOf returns the integer val as unsigned bits.
+func bitsOf(val reflect.Value) uint64 {
+	if isInt(val.Kind()) {
+		return uint64(val.Int())
+	}
+	return val.Uint()
+}
+
+// formatBits formats the integer val as a decimal number.
+func formatBits(val reflect.Value) string {
+	if isInt(val.Kind()) {
+		return strconv.FormatInt(val.Int(), 10)
+	}
+	return strconv.FormatUint(val.Uint(), 10)
+}
+
+// lookup returns the value of the given name, comparing case-insensitively if
+// fold is true and the name does not match exactly.
+func (e *enum) lookup(name string, fold bool) (uint64, bool) {
+	if v, ok := e.values[name]; ok {
+		return v, true
+	}
+	if fold {
+		for _, candidate := range e.names {
+			if strings.EqualFold(candidate, name) {
+				return e.values[candidate], true
+			}
+		}
+	}
+	return 0, false
+}
+
+// format returns the representation of the enum value val, i.e. its name, or
+// the names of its bits for flags. It reports false if val has no name.
+func (e *enum) format(val reflect.Value) (reflect.Value, bool) {
+	bits := bitsOf(val)
+	if !e.flags {
+		name, ok := e.byValue[bits]
+		return reflect.ValueOf(name), ok
+	}
+
+	// Single bits come first in names, so that they are preferred over
+	// combinations.
+	names := []string{}
+	rest := bits
+	for _, name := range e.names {
+		v := e.values[name]
+		if v != 0 && rest&v == v {
+			names = append(names, name)
+			rest &^= v
+		}
+	}
+	if rest != 0 {
+		return reflect.Value{}, false
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Compare(e.values[a], e.values[b])
+	})
+	return reflect.ValueOf(names), true
+}
+
+// parseKey converts the map key s into the enum by name, or as a number if
+// it is no name.
+func (e *enum) parseKey(s string, fold bool) (reflect.Value, *DecodeError) {
+	if v, ok := e.lookup(s, fold); ok {
+		key := reflect.New(e.typ).Elem()
+		setBits(key, v)
+		return key, nil
+	}
+	if key, err := parseKey(s, e.typ, "key"); err == nil {
+		return key, nil
+	}
+	err := keyErrorf(
+		ErrTypeMismatch,
+		"unknown %s %q, valid options are %s",
+		e.typ, s, strings.Join(e.names, ", "),
+	)
+	err.Suggestions = suggest(s, e.names)
+	return reflect.Value{}, err
+}
+
+// mapKeyName returns the name of the child of a map node with the given key,
+// i.e. the name of enum keys that have one, or the key itself.
+func mapKeyName(key reflect.Value) any {
+	val := key
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+	if val.IsValid() {
+		if e := enumOf(val.Type()); e != nil && !e.flags {
+			if name, ok := e.byValue[bitsOf(val)]; ok {
+				return name
+			}
+		}
+	}
+	return key.Interface()
+}
+
+// encodeEnum makes the node of an enum value hold its representation, if the
+// value has one.
+func (t *Tree) encodeEnum(e *enum) {
+	if repr, ok := e.format(t.val); ok {
+		t.extra().orig, t.val = t.val, repr
+	}
+}
+
+// isEnumSource reports whether node is decoded into the enum e by name.
+func isEnumSource(e *enum, node *Tree) bool {
+	if node.Value().Kind() == reflect.String {
+		return true
+	}
+	return e.flags && (node.kind() == reflect.Slice || node.kind() == reflect.Array)
+}
+
+// intoEnum decodes the name or, for flags, the list of names of node into the
+// enum target.
+func (dec *Decoding) intoEnum(e *enum, node *Tree, target DecodeTarget) error {
+	if node.Value().Kind() == reflect.String {
+		v, err := dec.enumValue(e, node, target)
+		if err != nil {
+			return err
+		}
+		setBits(target.Value, v)
+		return nil
+	}
+
+	var bits uint64
+	for child := range node.Children() {
+		if child.IsNil() || child.Value().Kind() != reflect.String {
+			return newDecodeErrorf(
+				ErrTypeMismatch,
+				child,
+				target,
+				"cannot decode %s into flag of %s", child.kind(), e.typ,
+			)
+		}
+		v, err := dec.enumValue(e, child, target)
+		if err != nil {
+			return err
+		}
+		bits |= v
+	}
+	setBits(target.Value, bits)
+	return nil
+}
+
+// enumValue returns the value of the name held by the string node.
+func (dec *Decoding) enumValue(e *enum, node *Tree, target DecodeTarget) (uint64, error) {
+	name := node.Value().String()
+	if v, ok := e.lookup(name, dec.FoldEnums); ok {
+		return v, nil
+	}
+	err := newDecodeErrorf(
+		ErrTypeMismatch,
+		node,
+		target,
+		"unknown %s %q, valid options are %s",
+		e.typ, name, strings.Join(e.names, ", "),
+	)
+	err.Suggestions = suggest(name, e.names)
+	return 0, err
+}
+
+// setBits sets the integer val to the unsigned bits.
+func setBits(val reflect.Value, bits uint64) {
+	if isInt(val.Kind()) {
+		val.SetInt(int64(bits))
+		return
+	}
+	val.SetUint(bits)
+}
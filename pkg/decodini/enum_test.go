@@ -0,0 +1,174 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+)
+
+func (l logLevel) String() string {
+	switch l {
+	case levelDebug:
+		return "debug"
+	case levelInfo:
+		return "info"
+	case levelWarn:
+		return "warn"
+	default:
+		return "unknown"
+	}
+}
+
+type permission uint8
+
+const (
+	permRead permission = 1 << iota
+	permWrite
+	permExec
+)
+
+func init() {
+	RegisterEnumValues(levelDebug, levelInfo, levelWarn)
+	RegisterFlags(map[string]permission{
+		"read":  permRead,
+		"write": permWrite,
+		"exec":  permExec,
+		"rw":    permRead | permWrite,
+	})
+}
+
+type logConfig struct {
+	Level logLevel   `decodini:"level"`
+	Perm  permission `decodini:"perm"`
+}
+
+func TestDecode_Enum(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"level": "warn", "perm": []any{"read", "exec"}}
+	to, err := Decode[logConfig](&Decoding{StructTag: "decodini"}, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(logConfig{Level: levelWarn, Perm: permRead | permExec}, to)
+
+	from = map[string]any{"level": 1, "perm": "rw"}
+	to, err = Decode[logConfig](&Decoding{StructTag: "decodini"}, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(logConfig{Level: levelInfo, Perm: permRead | permWrite}, to)
+}
+
+func TestDecode_Enum_Fold(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := Decode[logLevel](nil, Encode(nil, "INFO"))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	to, err := Decode[logLevel](&Decoding{FoldEnums: true}, Encode(nil, "INFO"))
+	a.NoError(err)
+	a.Equal(levelInfo, to)
+}
+
+func TestDecode_Enum_Unknown(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{"level": "wran", "perm": 0}
+	_, err := Decode[logConfig](&Decoding{StructTag: "decodini"}, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.EqualError(
+		err,
+		`decodini: decode: failed at level: unknown decodini.logLevel "wran", valid options are debug, info, warn (did you mean "warn"?)`,
+	)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal([]string{"warn"}, decErr.Suggestions)
+	}
+
+	from = map[string]any{"level": "info", "perm": []any{"read", "wirte"}}
+	_, err = Decode[logConfig](&Decoding{StructTag: "decodini"}, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.ErrorContains(err, "failed at perm.1")
+	a.ErrorContains(err, "valid options are read, write, rw, exec")
+}
+
+func TestEncode_Enum(t *testing.T) {
+	a := assert.New(t)
+
+	from := logConfig{Level: levelInfo, Perm: permRead | permWrite | permExec}
+	to, err := Transmute[map[string]any](nil, from)
+	a.NoError(err)
+	a.Equal(map[string]any{
+		"level": "info",
+		"perm":  []string{"read", "write", "exec"},
+	}, to)
+
+	back, err := Transmute[logConfig](nil, to)
+	a.NoError(err)
+	a.Equal(from, back)
+
+	// Values without name remain numbers.
+	to, err = Transmute[map[string]any](nil, logConfig{Level: 7, Perm: 1 << 7})
+	a.NoError(err)
+	a.Equal(map[string]any{"level": logLevel(7), "perm": permission(1 << 7)}, to)
+
+	// Plain numbers are decoded from the values of enums, not their names.
+	n, err := Transmute[int](nil, levelWarn)
+	a.NoError(err)
+	a.Equal(2, n)
+}
+
+func TestTransmuter_Enum(t *testing.T) {
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[logConfig, map[string]any](nil)
+	a.NoError(err)
+
+	to, err := tm.Run(logConfig{Level: levelDebug, Perm: permExec})
+	a.NoError(err)
+	a.Equal(map[string]any{"level": "debug", "perm": []string{"exec"}}, to)
+}
+
+func TestDecode_Enum_Keys(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := Transmute[map[logLevel]int](nil, map[string]int{"info": 3, "2": 4})
+	a.NoError(err)
+	a.Equal(map[logLevel]int{levelInfo: 3, levelWarn: 4}, to)
+
+	_, err = Transmute[map[logLevel]int](nil, map[string]int{"INFO": 3})
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.ErrorContains(err, "valid options are debug, info, warn")
+
+	to, err = Decode[map[logLevel]int](&Decoding{FoldEnums: true}, Encode(nil, map[string]int{"INFO": 3}))
+	a.NoError(err)
+	a.Equal(map[logLevel]int{levelInfo: 3}, to)
+
+	set, err := Transmute[map[logLevel]bool](nil, []string{"debug", "warn"})
+	a.NoError(err)
+	a.Equal(map[logLevel]bool{levelDebug: true, levelWarn: true}, set)
+}
+
+func TestEncode_Enum_Keys(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[logLevel]int{levelDebug: 1, levelWarn: 2, 7: 3}
+	to, err := Transmute[map[string]int](nil, from)
+	a.NoError(err)
+	a.Equal(map[string]int{"debug": 1, "warn": 2, "7": 3}, to)
+
+	back, err := Transmute[map[logLevel]int](nil, to)
+	a.NoError(err)
+	a.Equal(from, back)
+
+	child := Encode(nil, from).Child("warn")
+	if a.NotNil(child) {
+		a.Equal(2, child.Value().Interface())
+	}
+}
@@ -0,0 +1,222 @@
+package decodini
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// indexedChild is a child of a map node whose key denotes a slice index.
+type indexedChild struct {
+	index int
+	node  *Tree
+}
+
+// indexedChildren returns the children of the map node ordered by index if
+// all keys denote distinct slice indices.
+func indexedChildren(node *Tree) ([]indexedChild, bool) {
+	if node.NumChildren() == 0 {
+		return nil, false
+	}
+
+	children := make([]indexedChild, 0, node.NumChildren())
+	for child := range node.Children() {
+		index, ok := indexOf(child.Name())
+		if !ok {
+			return nil, false
+		}
+		children = append(children, indexedChild{index: index, node: child})
+	}
+
+	slices.SortFunc(children, func(a, b indexedChild) int {
+		return cmp.Compare(a.index, b.index)
+	})
+	for i := 1; i < len(children); i++ {
+		if children[i].index == children[i-1].index {
+			return nil, false
+		}
+	}
+	return children, true
+}
+
+// indexOf returns the slice index denoted by the map key name, which is
+// either a non-negative integer or its decimal string representation.
+// Indices that do not fit into int are returned as math.MaxInt.
+func indexOf(name any) (int, bool) {
+	val := reflect.ValueOf(name)
+	switch kind := val.Kind(); {
+	case isInt(kind):
+		i := val.Int()
+		if i > math.MaxInt {
+			return math.MaxInt, true
+		}
+		return int(i), i >= 0
+
+	case isUint(kind):
+		u := val.Uint()
+		return int(min(u, math.MaxInt)), true
+
+	case kind == reflect.String:
+		s := val.String()
+		i, err := strconv.Atoi(s)
+		if errors.Is(err, strconv.ErrRange) && isDecimal(s) {
+			return math.MaxInt, true
+		}
+		return i, err == nil && i >= 0 && strconv.Itoa(i) == s
+
+	default:
+		return 0, false
+	}
+}
+
+// isDecimal reports whether s is a non-negative decimal integer without
+// leading zeros.
+func isDecimal(s string) bool {
+	if s == "" || s[0] == '0' && len(s) > 1 {
+		return false
+	}
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// intoSliceFromIndexMap decodes a map whose keys denote slice indices into the
+// slice target. The slice is sized to the largest index plus one, which is
+// limited by Decoding.MaxElements.
+func (dec *Decoding) intoSliceFromIndexMap(
+	node *Tree,
+	target DecodeTarget,
+	children []indexedChild,
+) error {
+	last := children[len(children)-1]
+	if last.index == math.MaxInt {
+		return newDecodeErrorf(
+			ErrOverflow,
+			last.node,
+			target,
+			"index %v overflows int", last.node.Name(),
+		)
+	}
+
+	size := last.index + 1
+	if dec.MaxElements > 0 && size > dec.MaxElements {
+		return newDecodeErrorf(
+			ErrLimitExceeded,
+			node,
+			target,
+			"index %d exceeds maximum of %d elements",
+			size-1, dec.MaxElements,
+		)
+	}
+	if dec.RejectHoles && size != len(children) {
+		for i, child := range children {
+			if child.index != i {
+				return newDecodeErrorf(
+					ErrMissingIndex,
+					node.dummyChild(i),
+					target,
+					"index %d is missing in source map", i,
+				)
+			}
+		}
+	}
+
+	if target.Value.IsNil() || target.Value.Len() != size {
+		dst := reflect.MakeSlice(target.Value.Type(), size, size)
+		target.Value.Set(dst)
+	} else {
+		// Indices missing in the source are zero-filled.
+		for i := range size {
+			target.Value.Index(i).SetZero()
+		}
+	}
+	typ := inferType(node, target)
+
+	return dec.decodeElements(
+		target,
+		func(yield func(*Tree) bool) {
+			for _, child := range children {
+				if !yield(child.node) {
+					return
+				}
+			}
+		},
+		len(children),
+		func(i int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			val := reflect.New(typ.Elem()).Elem()
+			return val, dec.into(from, target.sub(children[i].index, val))
+		},
+		func(i int, _ *Tree, val reflect.Value) {
+			target.Value.Index(children[i].index).Set(val)
+		},
+	)
+}
+
+// intoMapFromSlice decodes a slice or array into the map target, keyed by the
+// indices of its elements. The key type must be an integer or string type.
+func (dec *Decoding) intoMapFromSlice(node *Tree, target DecodeTarget) error {
+	keyType := target.Value.Type().Key()
+	if !isInt(keyType.Kind()) && !isUint(keyType.Kind()) &&
+		keyType.Kind() != reflect.String {
+		return newDecodeErrorf(
+			ErrTypeMismatch,
+			node,
+			target,
+			"cannot use slice indices as map key of type %s", keyType,
+		)
+	}
+
+	if target.Value.IsNil() {
+		target.Value.Set(
+			reflect.MakeMapWithSize(target.Value.Type(), int(node.NumChildren())),
+		)
+	}
+	typ := inferType(node, target)
+
+	return dec.decodeElements(
+		target,
+		node.Children(),
+		int(node.NumChildren()),
+		func(i int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			key, ok := indexKey(keyType, i)
+			if !ok {
+				return reflect.Value{}, newDecodeErrorf(
+					ErrOverflow,
+					from,
+					target,
+					"index %d overflows %s", i, keyType,
+				)
+			}
+
+			val := reflect.New(typ.Elem()).Elem()
+			return val, dec.into(from, target.sub(key.Interface(), val))
+		},
+		func(i int, _ *Tree, val reflect.Value) {
+			key, _ := indexKey(keyType, i)
+			target.Value.SetMapIndex(key, val)
+		},
+	)
+}
+
+// indexKey returns the map key of type typ for the slice index i. It reports
+// false if i does not fit into typ.
+func indexKey(typ reflect.Type, i int) (reflect.Value, bool) {
+	key := reflect.New(typ).Elem()
+	if typ.Kind() == reflect.String {
+		key.SetString(strconv.Itoa(i))
+		return key, true
+	}
+
+	index := reflect.ValueOf(i)
+	if overflows(index, typ) {
+		return reflect.Value{}, false
+	}
+	key.Set(index.Convert(typ))
+	return key, true
+}
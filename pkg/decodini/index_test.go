@@ -0,0 +1,137 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode_IndexMap_to_Slice(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := Decode[[]string](nil, Encode(nil, map[string]string{"2": "c", "0": "a", "1": "b"}))
+	a.NoError(err)
+	a.Equal([]string{"a", "b", "c"}, to)
+
+	_, err = Decode[[]int](nil, Encode(nil, map[int]any{3: 30, 0: 0, 1: "foo"}))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: 1}}, decErr.Path())
+	}
+}
+
+func TestDecode_IndexMap_to_Slice_Holes(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]int{"3": 3, "0": 1}
+
+	to, err := Decode[[]int](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal([]int{1, 0, 0, 3}, to)
+
+	_, err = Decode[[]int](&Decoding{RejectHoles: true}, Encode(nil, from))
+	a.ErrorIs(err, ErrMissingIndex)
+	a.EqualError(err, "decodini: decode: failed at 1: index 1 is missing in source map")
+}
+
+func TestDecode_IndexMap_to_Slice_MaxElements(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]int{"1000000000": 1}
+	_, err := Decode[[]int](&Decoding{MaxElements: 100}, Encode(nil, from))
+	a.ErrorIs(err, ErrLimitExceeded)
+}
+
+func TestDecode_IndexMap_to_Slice_Overflow(t *testing.T) {
+	a := assert.New(t)
+
+	for _, from := range []any{
+		map[string]any{"9223372036854775807": "x"},
+		map[string]any{"99999999999999999999": "x"},
+		map[uint64]any{1 << 63: "x"},
+	} {
+		_, err := Transmute[[]string](nil, from)
+		a.ErrorIs(err, ErrOverflow)
+	}
+}
+
+func TestDecode_IndexMap_to_Slice_Sparse(t *testing.T) {
+	a := assert.New(t)
+
+	dec := &Decoding{MaxElements: 1024}
+	_, err := Decode[[]string](dec, Encode(nil, map[string]any{"8589934592": "x"}))
+	a.ErrorIs(err, ErrLimitExceeded)
+	a.EqualError(err, "decodini: decode: failed at <root>: index 8589934592 exceeds maximum of 1024 elements")
+
+	to, err := Decode[[]string](dec, Encode(nil, map[string]any{"1023": "x"}))
+	a.NoError(err)
+	a.Len(to, 1024)
+
+	to, err = Transmute[[]string](nil, map[string]any{"5000": "c"})
+	a.NoError(err)
+	a.Len(to, 5001)
+	a.Equal("c", to[5000])
+}
+
+func TestDecode_IndexMap_to_LongerSlice(t *testing.T) {
+	a := assert.New(t)
+
+	to := []string{"x", "y", "z"}
+	err := DecodeInto(nil, Encode(nil, map[string]any{"1": "b"}), &to)
+	a.NoError(err)
+	a.Equal([]string{"", "b"}, to)
+}
+
+func TestDecode_NonIndexMap_to_Slice(t *testing.T) {
+	a := assert.New(t)
+
+	for _, from := range []map[string]int{
+		{"a": 1, "0": 2},
+		{"01": 1, "0": 2},
+		{"-1": 1, "0": 2},
+	} {
+		to, err := Decode[[]int](nil, Encode(nil, from))
+		a.NoError(err)
+		a.ElementsMatch([]int{1, 2}, to)
+	}
+}
+
+func TestDecode_Slice_to_IndexMap(t *testing.T) {
+	a := assert.New(t)
+
+	from := []string{"a", "b", "c"}
+
+	toStrings, err := Decode[map[string]string](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(map[string]string{"0": "a", "1": "b", "2": "c"}, toStrings)
+
+	toInts, err := Decode[map[uint8]string](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(map[uint8]string{0: "a", 1: "b", 2: "c"}, toInts)
+
+	back, err := Decode[[]string](nil, Encode(nil, toStrings))
+	a.NoError(err)
+	a.Equal(from, back)
+
+	_, err = Decode[map[bool]string](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	_, err = Decode[map[int8]int](nil, Encode(nil, make([]int, 200)))
+	a.ErrorIs(err, ErrOverflow)
+}
+
+func TestTransmuter_Slice_to_IndexMap(t *testing.T) {
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[[]int, map[string]int](nil)
+	a.NoError(err)
+
+	to, err := tm.Run([]int{1, 2})
+	a.NoError(err)
+	a.Equal(map[string]int{"0": 1, "1": 2}, to)
+
+	_, err = NewTransmuter[[]int, map[float64]int](nil)
+	a.ErrorIs(err, ErrTypeMismatch)
+}
@@ -0,0 +1,199 @@
+package decodini
+
+import (
+	"reflect"
+	"strings"
+)
+
+// keyBy returns the path of the element field the map or slice target is keyed
+// by, as set by the keyby tag option of its struct field, e.g.
+// `decodini:"services,keyby=name"`. Nested fields are separated by dots, e.g.
+// `keyby=meta.name`. The path consists of field names as seen through the
+// decoding tag.
+func (dec *Decoding) keyBy(target DecodeTarget) ([]string, bool) {
+	raw, ok := target.field.option("keyby")
+	if !ok || raw == "" {
+		return nil, false
+	}
+	return strings.Split(raw, "."), true
+}
+
+// hasKeyBy reports whether typ is a struct type with fields that have the keyby
+// or entries option in the given tag.
+func hasKeyBy(tag string, typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct &&
+		structPlanOf(tag, typ).hasOption("keyby", "entries")
+}
+
+// keyField returns the field at path within the struct elem, following
+// pointers. If alloc is true, nil pointers on the way are allocated, otherwise
+// they are reported as error. The returned error is not yet associated with a
+// node or target.
+func keyField(
+	tag string,
+	elem reflect.Value,
+	path []string,
+	alloc bool,
+) (reflect.Value, *DecodeError) {
+	for _, name := range path {
+		for elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				if !alloc {
+					return reflect.Value{}, keyErrorf(
+						ErrTypeMismatch,
+						"cannot read key field %s of nil %s", name, elem.Type(),
+					)
+				}
+				elem.Set(reflect.New(elem.Type().Elem()))
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return reflect.Value{}, keyErrorf(
+				ErrUnsupportedKind,
+				"cannot use field %s of %s as key", name, elem.Type(),
+			)
+		}
+
+		plan := structPlanOf(tag, elem.Type())
+		i, ok := plan.byName[name]
+		if !ok {
+			return reflect.Value{}, keyErrorf(
+				ErrUnmatchedField,
+				"key field %s does not exist in %s", name, elem.Type(),
+			)
+		}
+
+		index := plan.flat[i].index
+		if alloc {
+			elem = settableField(elem, index)
+			continue
+		}
+		field, err := elem.FieldByIndexErr(index)
+		if err != nil {
+			return reflect.Value{}, keyErrorf(
+				ErrTypeMismatch,
+				"cannot read key field %s: %w", name, err,
+			)
+		}
+		elem = field
+	}
+	return elem, nil
+}
+
+// intoMapFromSliceByKey decodes the elements of a slice or array into the map
+// target, keyed by the value of their field at path. Elements with the same key
+// are rejected.
+func (dec *Decoding) intoMapFromSliceByKey(
+	node *Tree,
+	target DecodeTarget,
+	path []string,
+) error {
+	typ := inferType(node, target)
+	keyType := typ.Key()
+
+	n := int(node.NumChildren())
+	keys := make([]reflect.Value, n)
+	vals := make([]reflect.Value, n)
+	froms := make([]*Tree, n)
+
+	err := dec.decodeElements(
+		target,
+		node.Children(),
+		n,
+		func(i int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			val := reflect.New(typ.Elem()).Elem()
+			if err := dec.into(from, target.sub(from.Name(), val)); err != nil {
+				return reflect.Value{}, err
+			}
+
+			field, err := keyField(dec.StructTag, val, path, false)
+			if err == nil {
+				keys[i], err = convertKey(field.Interface(), keyType, dec.FoldEnums)
+			}
+			if err == nil && !keys[i].Comparable() {
+				err = keyErrorf(
+					ErrTypeMismatch,
+					"cannot use key of type %s as map key", keys[i].Type(),
+				)
+			}
+			if err != nil {
+				err.From, err.Into = from, target
+				return reflect.Value{}, err
+			}
+			return val, nil
+		},
+		func(i int, from *Tree, val reflect.Value) {
+			vals[i], froms[i] = val, from
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if target.Value.IsNil() {
+		target.Value.Set(reflect.MakeMapWithSize(target.Value.Type(), n))
+	}
+	seen := make(map[any]int, n)
+	for i := range n {
+		key := keys[i].Interface()
+		if prev, ok := seen[key]; ok {
+			return newDecodeErrorf(
+				ErrDuplicateKey,
+				froms[i],
+				target,
+				"key %v of element %v is already used by element %v",
+				key, froms[i].Name(), froms[prev].Name(),
+			)
+		}
+		seen[key] = i
+		target.Value.SetMapIndex(keys[i], vals[i])
+	}
+	return nil
+}
+
+// intoSliceFromMapByKey decodes the values of a map into the slice target and
+// writes their keys into the field at path of the elements.
+func (dec *Decoding) intoSliceFromMapByKey(
+	node *Tree,
+	target DecodeTarget,
+	path []string,
+) error {
+	nChildren := int(node.NumChildren())
+	if target.Value.IsNil() || target.Value.Len() != nChildren {
+		target.Value.Set(
+			reflect.MakeSlice(target.Value.Type(), nChildren, nChildren),
+		)
+	}
+	typ := inferType(node, target)
+
+	return dec.decodeElements(
+		target,
+		node.Children(),
+		nChildren,
+		func(i int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			sub := target.sub(i, reflect.New(typ.Elem()).Elem())
+			sub.keyBy = path
+			if err := dec.into(from, sub); err != nil {
+				return reflect.Value{}, err
+			}
+
+			field, err := keyField(dec.StructTag, sub.Value, path, true)
+			if err == nil {
+				var key reflect.Value
+				key, err = convertKey(from.Name(), field.Type(), dec.FoldEnums)
+				if err == nil {
+					field.Set(key)
+				}
+			}
+			if err != nil {
+				err.From, err.Into = from, sub
+				return reflect.Value{}, err
+			}
+			return sub.Value, nil
+		},
+		func(i int, _ *Tree, val reflect.Value) {
+			target.Value.Index(i).Set(val)
+		},
+	)
+}
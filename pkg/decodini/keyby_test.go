@@ -0,0 +1,123 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type keyedService struct {
+	Name string `decodini:"name"`
+	Port int    `decodini:"port"`
+}
+
+func TestDecode_Slice_to_MapByKey(t *testing.T) {
+	type registry struct {
+		Services map[string]keyedService `decodini:"services,keyby=name"`
+	}
+
+	a := assert.New(t)
+
+	from := map[string]any{
+		"services": []any{
+			map[string]any{"name": "web", "port": 80},
+			map[string]any{"name": "db", "port": 5432},
+		},
+	}
+	to, err := Decode[registry](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(map[string]keyedService{
+		"web": {Name: "web", Port: 80},
+		"db":  {Name: "db", Port: 5432},
+	}, to.Services)
+
+	from["services"] = []any{
+		map[string]any{"name": "web", "port": 80},
+		map[string]any{"name": "web", "port": 8080},
+	}
+	_, err = Decode[registry](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrDuplicateKey)
+	a.EqualError(
+		err,
+		"decodini: decode: failed at services.1: key web of element 1 is already used by element 0",
+	)
+}
+
+func TestDecode_Slice_to_MapByKey_Nested(t *testing.T) {
+	type meta struct {
+		ID int `decodini:"id"`
+	}
+	type item struct {
+		Meta  *meta  `decodini:"meta"`
+		Label string `decodini:"label"`
+	}
+	type holder struct {
+		Items map[string]*item `decodini:"items,keyby=meta.id"`
+	}
+
+	a := assert.New(t)
+
+	from := map[string]any{
+		"items": []any{
+			map[string]any{"meta": map[string]any{"id": 7}, "label": "seven"},
+		},
+	}
+	to, err := Decode[holder](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(map[string]*item{"7": {Meta: &meta{ID: 7}, Label: "seven"}}, to.Items)
+
+	from["items"] = []any{map[string]any{"meta": nil, "label": "none"}}
+	_, err = Decode[holder](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "items"}, Index(0)}, decErr.Path())
+	}
+}
+
+func TestDecode_Map_to_SliceByKey(t *testing.T) {
+	type registry struct {
+		Services []keyedService `decodini:"services,keyby=name"`
+	}
+
+	a := assert.New(t)
+
+	from := map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{"port": 80},
+		},
+	}
+	to, err := Decode[registry](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal([]keyedService{{Name: "web", Port: 80}}, to.Services)
+
+	type badRegistry struct {
+		Services []keyedService `decodini:"services,keyby=id"`
+	}
+	_, err = Decode[badRegistry](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrUnmatchedField)
+}
+
+func TestTransmute_KeyBy_RoundTrip(t *testing.T) {
+	type api struct {
+		Services []keyedService `decodini:"services"`
+	}
+	type internal struct {
+		Services map[string]keyedService `decodini:"services,keyby=name"`
+	}
+
+	a := assert.New(t)
+
+	from := api{Services: []keyedService{{Name: "web", Port: 80}}}
+	to, err := Transmute[internal](nil, from)
+	a.NoError(err)
+	a.Equal(map[string]keyedService{"web": {Name: "web", Port: 80}}, to.Services)
+
+	tm, err := NewTransmuter[api, internal](nil)
+	a.NoError(err)
+
+	toCompiled, err := tm.Run(from)
+	a.NoError(err)
+	a.Equal(to, toCompiled)
+}
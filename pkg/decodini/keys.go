@@ -0,0 +1,131 @@
+package decodini
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// convertKey converts the map key or field name key into a map key of type
+// typ. The returned error is not yet associated with a node or target.
+//
+// Keys follow the scalar decoding rules, except that conversions losing
+// information are rejected, as they could merge distinct keys. As map keys are
+// commonly strings in text formats, string keys are also parsed into numbers,
+// booleans and types implementing encoding.TextUnmarshaler, and numbers and
+// booleans are formatted into strings. Like values, string keys are looked up
+// by name for registered enums, case-insensitively if fold is true.
+func convertKey(key any, typ reflect.Type, fold bool) (reflect.Value, *DecodeError) {
+	return convertScalar(key, typ, fold, "key")
+}
+
+// convertScalar converts key like convertKey, naming it noun in error
+// messages.
+func convertScalar(key any, typ reflect.Type, fold bool, noun string) (reflect.Value, *DecodeError) {
+	val := reflect.ValueOf(key)
+	switch {
+	case !val.IsValid():
+		if typ.Kind() == reflect.Interface {
+			return reflect.Zero(typ), nil
+		}
+		return reflect.Value{}, keyErrorf(
+			ErrTypeMismatch,
+			"cannot use nil %s as %s", noun, typ,
+		)
+
+	case val.Type().AssignableTo(typ):
+		return val, nil
+
+	case val.Kind() == reflect.String && enumOf(typ) != nil:
+		return enumOf(typ).parseKey(val.String(), fold)
+
+	case val.Kind() == reflect.String && reflect.PointerTo(typ).Implements(textUnmarshalerType):
+		ptr := reflect.New(typ)
+		err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val.String()))
+		if err != nil {
+			return reflect.Value{}, keyErrorf(
+				ErrTypeMismatch,
+				"cannot use %s %q as %s: %w", noun, val.String(), typ, err,
+			)
+		}
+		return ptr.Elem(), nil
+
+	case val.Kind() == reflect.String && (isNumeric(typ.Kind()) || typ.Kind() == reflect.Bool):
+		return parseKey(val.String(), typ, noun)
+
+	case typ.Kind() == reflect.String && enumOf(val.Type()) != nil:
+		// Enum keys without name are formatted as numbers rather than by their
+		// String method.
+		return reflect.ValueOf(formatBits(val)).Convert(typ), nil
+
+	case typ.Kind() == reflect.String && (isNumeric(val.Kind()) || val.Kind() == reflect.Bool):
+		return reflect.ValueOf(fmt.Sprint(val)).Convert(typ), nil
+
+	case isNumeric(val.Kind()) && isNumeric(typ.Kind()):
+		if overflows(val, typ) {
+			return reflect.Value{}, keyErrorf(ErrOverflow, "%s %v overflows %s", noun, val, typ)
+		}
+		conv := val.Convert(typ)
+		if !conv.Convert(val.Type()).Equal(val) {
+			return reflect.Value{}, keyErrorf(
+				ErrTypeMismatch,
+				"%s %v is not representable by %s", noun, val, typ,
+			)
+		}
+		return conv, nil
+
+	case val.Kind() == typ.Kind() && val.Type().ConvertibleTo(typ):
+		return val.Convert(typ), nil
+
+	default:
+		return reflect.Value{}, keyErrorf(
+			ErrTypeMismatch,
+			"cannot use %s of type %s as %s", noun, val.Type(), typ,
+		)
+	}
+}
+
+// parseKey parses the string s into the numeric or boolean type typ, naming it
+// noun in error messages.
+func parseKey(s string, typ reflect.Type, noun string) (reflect.Value, *DecodeError) {
+	key := reflect.New(typ).Elem()
+
+	var err error
+	switch kind := typ.Kind(); {
+	case kind == reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		key.SetBool(b)
+	case isInt(kind):
+		var i int64
+		i, err = strconv.ParseInt(s, 10, typ.Bits())
+		key.SetInt(i)
+	case isUint(kind):
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, typ.Bits())
+		key.SetUint(u)
+	default:
+		var f float64
+		f, err = strconv.ParseFloat(s, typ.Bits())
+		key.SetFloat(f)
+	}
+
+	if numErr, ok := err.(*strconv.NumError); ok {
+		kind := ErrTypeMismatch
+		if numErr.Err == strconv.ErrRange {
+			kind = ErrOverflow
+		}
+		return reflect.Value{}, keyErrorf(
+			kind,
+			"cannot use %s %q as %s: %w", noun, s, typ, numErr.Err,
+		)
+	}
+	return key, nil
+}
+
+func keyErrorf(kind error, format string, args ...any) *DecodeError {
+	return &DecodeError{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,110 @@
+package decodini
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upperKey string
+
+func (k *upperKey) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("empty key")
+	}
+	*k = upperKey(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestDecode_MapKeys_StringToNumber(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := Decode[map[int]string](nil, Encode(nil, map[string]any{"1": "a", "-2": "b"}))
+	a.NoError(err)
+	a.Equal(map[int]string{1: "a", -2: "b"}, to)
+
+	toFloat, err := Decode[map[float64]bool](nil, Encode(nil, map[string]bool{"1.5": true}))
+	a.NoError(err)
+	a.Equal(map[float64]bool{1.5: true}, toFloat)
+
+	_, err = Decode[map[int]string](nil, Encode(nil, map[string]string{"x": "a"}))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "x"}}, decErr.Path())
+	}
+
+	_, err = Decode[map[int8]string](nil, Encode(nil, map[string]string{"300": "a"}))
+	a.ErrorIs(err, ErrOverflow)
+}
+
+func TestDecode_MapKeys_NumberToString(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := Decode[map[string]int](nil, Encode(nil, map[int]int{1: 1, 20: 2}))
+	a.NoError(err)
+	a.Equal(map[string]int{"1": 1, "20": 2}, to)
+}
+
+func TestDecode_MapKeys_Numeric(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := Decode[map[int64]string](nil, Encode(nil, map[any]string{int8(1): "a", 2.0: "b"}))
+	a.NoError(err)
+	a.Equal(map[int64]string{1: "a", 2: "b"}, to)
+
+	_, err = Decode[map[int]string](nil, Encode(nil, map[float64]string{1.5: "a"}))
+	a.ErrorIs(err, ErrTypeMismatch)
+}
+
+func TestDecode_MapKeys_NamedAndTextUnmarshaler(t *testing.T) {
+	type name string
+
+	a := assert.New(t)
+
+	toNamed, err := Decode[map[name]int](nil, Encode(nil, map[string]int{"a": 1}))
+	a.NoError(err)
+	a.Equal(map[name]int{"a": 1}, toNamed)
+
+	toUpper, err := Decode[map[upperKey]int](nil, Encode(nil, map[string]int{"a": 1}))
+	a.NoError(err)
+	a.Equal(map[upperKey]int{"A": 1}, toUpper)
+
+	_, err = Decode[map[upperKey]int](nil, Encode(nil, map[string]int{"": 1}))
+	a.ErrorIs(err, ErrTypeMismatch)
+}
+
+func TestDecode_AnyKeyMap_to_Struct(t *testing.T) {
+	type toStruct struct {
+		Name string `decodini:"name"`
+		Port int    `decodini:"port"`
+	}
+
+	a := assert.New(t)
+
+	from := map[any]any{"name": "foo", "port": 80}
+	to, err := Decode[toStruct](&Decoding{Strict: true}, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(toStruct{Name: "foo", Port: 80}, to)
+
+	_, err = Decode[toStruct](nil, Encode(nil, map[any]any{"name": "foo", "prot": 80}))
+	a.ErrorIs(err, ErrUnmatchedField)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal([]string{"prot"}, decErr.Suggestions)
+	}
+}
+
+func TestTree_Child_ConvertsKey(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, map[int]string{1: "a"})
+	if child := tr.Child("1"); a.NotNil(child) {
+		a.Equal("a", child.Value().Interface())
+	}
+	a.Nil(tr.Child("x"))
+}
@@ -0,0 +1,67 @@
+package decodini
+
+import "reflect"
+
+// cancelCheckInterval is the number of visited nodes after which the context
+// of a decoding run is checked for cancellation.
+const cancelCheckInterval = 256
+
+// checkLimits verifies that decoding node into target stays within the
+// resource limits of dec and that the context has not been cancelled.
+func (dec *Decoding) checkLimits(node *Tree, target DecodeTarget) error {
+	if target.state != nil {
+		target.state.nodes++
+		if target.state.nodes%cancelCheckInterval == 1 && target.state.ctx != nil {
+			if err := target.state.ctx.Err(); err != nil {
+				return newDecodeError(nil, node, target, err)
+			}
+		}
+		if dec.MaxNodes > 0 && target.state.nodes > dec.MaxNodes {
+			return newDecodeErrorf(
+				ErrLimitExceeded,
+				node,
+				target,
+				"exceeded maximum of %d nodes", dec.MaxNodes,
+			)
+		}
+	}
+
+	if dec.MaxDepth > 0 && target.depth > dec.MaxDepth {
+		return newDecodeErrorf(
+			ErrLimitExceeded,
+			node,
+			target,
+			"exceeded maximum depth of %d", dec.MaxDepth,
+		)
+	}
+
+	if node.IsNil() {
+		return nil
+	}
+
+	switch node.Value().Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		if dec.MaxElements > 0 && node.NumChildren() > uint(dec.MaxElements) {
+			return newDecodeErrorf(
+				ErrLimitExceeded,
+				node,
+				target,
+				"%d elements exceed maximum of %d",
+				node.NumChildren(), dec.MaxElements,
+			)
+		}
+
+	case reflect.String:
+		if dec.MaxStringLength > 0 && node.Value().Len() > dec.MaxStringLength {
+			return newDecodeErrorf(
+				ErrLimitExceeded,
+				node,
+				target,
+				"string of length %d exceeds maximum of %d",
+				node.Value().Len(), dec.MaxStringLength,
+			)
+		}
+	}
+
+	return nil
+}
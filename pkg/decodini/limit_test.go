@@ -0,0 +1,80 @@
+package decodini
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode_MaxDepth(t *testing.T) {
+	a := assert.New(t)
+
+	from := map[string]any{
+		"a": map[string]any{
+			"b": map[string]any{
+				"c": 1,
+			},
+		},
+	}
+	tr := Encode(nil, from)
+
+	_, err := Decode[map[string]any](&Decoding{MaxDepth: 3}, tr)
+	a.NoError(err)
+
+	_, err = Decode[map[string]any](&Decoding{MaxDepth: 2}, tr)
+	a.ErrorIs(err, ErrLimitExceeded)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "a"}, Key{Value: "b"}, Key{Value: "c"}}, decErr.Path())
+	}
+}
+
+func TestDecode_MaxElements(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{
+		"items": []int{1, 2, 3},
+	})
+
+	_, err := Decode[map[string][]int](&Decoding{MaxElements: 3}, tr)
+	a.NoError(err)
+
+	_, err = Decode[map[string][]int](&Decoding{MaxElements: 2}, tr)
+	a.ErrorIs(err, ErrLimitExceeded)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "items"}}, decErr.Path())
+	}
+}
+
+func TestDecode_MaxNodes(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, []int{1, 2, 3})
+
+	_, err := Decode[[]int](&Decoding{MaxNodes: 4}, tr)
+	a.NoError(err)
+
+	_, err = Decode[[]int](&Decoding{MaxNodes: 3}, tr)
+	a.ErrorIs(err, ErrLimitExceeded)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Index(2)}, decErr.Path())
+	}
+}
+
+func TestDecode_MaxStringLength(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, []string{"foo", strings.Repeat("x", 10)})
+
+	_, err := Decode[[]string](&Decoding{MaxStringLength: 10}, tr)
+	a.NoError(err)
+
+	_, err = Decode[[]string](&Decoding{MaxStringLength: 9}, tr)
+	a.ErrorIs(err, ErrLimitExceeded)
+}
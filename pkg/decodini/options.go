@@ -0,0 +1,215 @@
+package decodini
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOption is reported by NewEncoding, NewDecoding and
+// NewTransmutation if an option is invalid.
+var ErrInvalidOption = errors.New("decodini: invalid option")
+
+// Option configures the Encoding and Decoding of a Transmutation.
+type Option func(*Transmutation)
+
+// NewTransmutation returns a validated Transmutation configured by opts. The
+// encoding and decoding use the same struct tag unless configured otherwise.
+//
+// The Transmutation is returned by value and shares no memory with other
+// configurations. It is never modified by this package, so it is safe for
+// concurrent use.
+func NewTransmutation(opts ...Option) (Transmutation, error) {
+	tr := Transmutation{Encoding: defaultEncoding, Decoding: defaultDecoding}
+	for _, opt := range opts {
+		opt(&tr)
+	}
+	if err := tr.validate(); err != nil {
+		return Transmutation{}, err
+	}
+	return tr, nil
+}
+
+// NewEncoding returns a validated Encoding configured by opts. Options that
+// only affect decoding are ignored.
+func NewEncoding(opts ...Option) (Encoding, error) {
+	tr, err := NewTransmutation(opts...)
+	return tr.Encoding, err
+}
+
+// NewDecoding returns a validated Decoding configured by opts.
+func NewDecoding(opts ...Option) (Decoding, error) {
+	tr, err := NewTransmutation(opts...)
+	return tr.Decoding, err
+}
+
+func (tr *Transmutation) validate() error {
+	switch {
+	case tr.Encoding.Cycles < CycleError || tr.Encoding.Cycles > CycleRef:
+		return fmt.Errorf(
+			"%w: unknown cycle mode %d", ErrInvalidOption, tr.Encoding.Cycles,
+		)
+	case !tr.Encoding.Bytes.valid():
+		return fmt.Errorf(
+			"%w: unknown encode byte encoding %d", ErrInvalidOption, tr.Encoding.Bytes,
+		)
+	case !tr.Decoding.Bytes.valid():
+		return fmt.Errorf(
+			"%w: unknown decode byte encoding %d", ErrInvalidOption, tr.Decoding.Bytes,
+		)
+	case tr.Encoding.StructTag == "":
+		return fmt.Errorf("%w: encode struct tag must not be empty", ErrInvalidOption)
+	case tr.Decoding.StructTag == "":
+		return fmt.Errorf("%w: decode struct tag must not be empty", ErrInvalidOption)
+	}
+
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"max depth", tr.Decoding.MaxDepth},
+		{"max elements", tr.Decoding.MaxElements},
+		{"max nodes", tr.Decoding.MaxNodes},
+		{"max string length", tr.Decoding.MaxStringLength},
+		{"parallelism", tr.Decoding.Parallelism},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			return fmt.Errorf(
+				"%w: %s must not be negative, got %d",
+				ErrInvalidOption, limit.name, limit.value,
+			)
+		}
+	}
+	return nil
+}
+
+// WithTag sets the struct tag used for both encoding and decoding.
+func WithTag(tag string) Option {
+	return func(tr *Transmutation) {
+		tr.Encoding.StructTag = tag
+		tr.Decoding.StructTag = tag
+	}
+}
+
+// WithEncodeTag sets the struct tag used for encoding.
+func WithEncodeTag(tag string) Option {
+	return func(tr *Transmutation) { tr.Encoding.StructTag = tag }
+}
+
+// WithDecodeTag sets the struct tag used for decoding.
+func WithDecodeTag(tag string) Option {
+	return func(tr *Transmutation) { tr.Decoding.StructTag = tag }
+}
+
+// WithCycles sets Encoding.Cycles.
+func WithCycles(mode CycleMode) Option {
+	return func(tr *Transmutation) { tr.Encoding.Cycles = mode }
+}
+
+// WithSortedMapKeys enables Encoding.SortMapKeys, using compare as
+// Encoding.CompareMapKeys.
+func WithSortedMapKeys(compare func(a, b any) int) Option {
+	return func(tr *Transmutation) {
+		tr.Encoding.SortMapKeys = true
+		tr.Encoding.CompareMapKeys = compare
+	}
+}
+
+// WithSets enables Encoding.Sets.
+func WithSets() Option {
+	return func(tr *Transmutation) { tr.Encoding.Sets = true }
+}
+
+// WithEntries sets Encoding.Entries and Decoding.Entries to names.
+func WithEntries(names EntryNames) Option {
+	return func(tr *Transmutation) {
+		tr.Encoding.Entries = names
+		tr.Decoding.Entries = names
+	}
+}
+
+// WithDecoder sets Decoding.Decoder.
+func WithDecoder(fn func(tr *Tree, target DecodeTarget) Decoder) Option {
+	return func(tr *Transmutation) { tr.Decoding.Decoder = fn }
+}
+
+// WithUnmatched sets Decoding.Unmatched, e.g. to DecodeIgnoreUnmatched.
+func WithUnmatched(fn func(tr *Tree, target DecodeTarget) (*Tree, error)) Option {
+	return func(tr *Transmutation) { tr.Decoding.Unmatched = fn }
+}
+
+// WithWarn sets Decoding.Warn.
+func WithWarn(fn func(w Warning)) Option {
+	return func(tr *Transmutation) { tr.Decoding.Warn = fn }
+}
+
+// WithStrict enables Decoding.Strict.
+func WithStrict() Option {
+	return func(tr *Transmutation) { tr.Decoding.Strict = true }
+}
+
+// WithRejectHoles enables Decoding.RejectHoles.
+func WithRejectHoles() Option {
+	return func(tr *Transmutation) { tr.Decoding.RejectHoles = true }
+}
+
+// WithRejectDuplicates enables Decoding.RejectDuplicates.
+func WithRejectDuplicates() Option {
+	return func(tr *Transmutation) { tr.Decoding.RejectDuplicates = true }
+}
+
+// WithSingletons enables Decoding.Singletons.
+func WithSingletons() Option {
+	return func(tr *Transmutation) { tr.Decoding.Singletons = true }
+}
+
+// WithFoldEnums enables Decoding.FoldEnums.
+func WithFoldEnums() Option {
+	return func(tr *Transmutation) { tr.Decoding.FoldEnums = true }
+}
+
+// WithSeparator sets Decoding.Separator.
+func WithSeparator(sep string) Option {
+	return func(tr *Transmutation) { tr.Decoding.Separator = sep }
+}
+
+// WithDelimiting sets the Delimiting of both the encoding and decoding.
+func WithDelimiting(d Delimiting) Option {
+	return func(tr *Transmutation) {
+		tr.Encoding.Delimiting = d
+		tr.Decoding.Delimiting = d
+	}
+}
+
+// WithBytes sets the ByteEncoding of both the encoding and decoding.
+func WithBytes(e ByteEncoding) Option {
+	return func(tr *Transmutation) {
+		tr.Encoding.Bytes = e
+		tr.Decoding.Bytes = e
+	}
+}
+
+// WithMaxDepth sets Decoding.MaxDepth.
+func WithMaxDepth(n int) Option {
+	return func(tr *Transmutation) { tr.Decoding.MaxDepth = n }
+}
+
+// WithMaxElements sets Decoding.MaxElements.
+func WithMaxElements(n int) Option {
+	return func(tr *Transmutation) { tr.Decoding.MaxElements = n }
+}
+
+// WithMaxNodes sets Decoding.MaxNodes.
+func WithMaxNodes(n int) Option {
+	return func(tr *Transmutation) { tr.Decoding.MaxNodes = n }
+}
+
+// WithMaxStringLength sets Decoding.MaxStringLength.
+func WithMaxStringLength(n int) Option {
+	return func(tr *Transmutation) { tr.Decoding.MaxStringLength = n }
+}
+
+// WithParallelism sets Decoding.Parallelism.
+func WithParallelism(n int) Option {
+	return func(tr *Transmutation) { tr.Decoding.Parallelism = n }
+}
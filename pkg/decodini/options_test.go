@@ -0,0 +1,85 @@
+package decodini
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransmutation(t *testing.T) {
+	a := assert.New(t)
+
+	tr, err := NewTransmutation(WithTag("json"), WithStrict(), WithMaxDepth(8))
+	a.NoError(err)
+	a.Equal("json", tr.Encoding.StructTag)
+	a.Equal("json", tr.Decoding.StructTag)
+	a.True(tr.Decoding.Strict)
+	a.Equal(8, tr.Decoding.MaxDepth)
+
+	tr, err = NewTransmutation(WithTag("json"), WithDecodeTag("yaml"))
+	a.NoError(err)
+	a.Equal("json", tr.Encoding.StructTag)
+	a.Equal("yaml", tr.Decoding.StructTag)
+
+	tr, err = NewTransmutation()
+	a.NoError(err)
+	a.Equal(defaultEncoding, tr.Encoding)
+	a.Equal(defaultDecoding.StructTag, tr.Decoding.StructTag)
+}
+
+func TestNewTransmutation_Invalid(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := NewTransmutation(WithTag(""))
+	a.ErrorIs(err, ErrInvalidOption)
+
+	_, err = NewDecoding(WithMaxNodes(-1))
+	a.ErrorIs(err, ErrInvalidOption)
+	a.EqualError(err, "decodini: invalid option: max nodes must not be negative, got -1")
+
+	_, err = NewEncoding(WithParallelism(-2))
+	a.ErrorIs(err, ErrInvalidOption)
+}
+
+func TestNewTransmutation_ReturnsValues(t *testing.T) {
+	a := assert.New(t)
+
+	tr, err := NewTransmutation(WithTag("json"))
+	a.NoError(err)
+	dec, err := NewDecoding(WithTag("json"))
+	a.NoError(err)
+
+	tr.Decoding.StructTag = "yaml"
+	dec.StructTag = "yaml"
+
+	again, err := NewTransmutation(WithTag("json"))
+	a.NoError(err)
+	a.Equal("json", again.Decoding.StructTag)
+	a.Equal("decodini", defaultDecoding.StructTag)
+}
+
+func TestDecodeInto_DoesNotModifyDecoding(t *testing.T) {
+	type toStruct struct {
+		A string `decodini:"a"`
+	}
+
+	a := assert.New(t)
+
+	tr := &Transmutation{}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			to, err := Transmute[toStruct](tr, map[string]any{"a": "foo"})
+			a.NoError(err)
+			a.Equal(toStruct{A: "foo"}, to)
+		}()
+	}
+	wg.Wait()
+
+	a.Empty(tr.Encoding.StructTag)
+	a.Empty(tr.Decoding.StructTag)
+}
@@ -0,0 +1,148 @@
+package decodini
+
+import (
+	"iter"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// minParallelElements is the minimum number of elements of a collection for
+// its elements to be decoded concurrently. Smaller collections are not worth
+// the overhead.
+const minParallelElements = 16
+
+// elementDecoder decodes the i-th element from of a collection into a fresh
+// value, using target as the parent target.
+type elementDecoder func(i int, from *Tree, target DecodeTarget) (reflect.Value, error)
+
+// elementCommitter stores the decoded value of the i-th element from into the
+// collection.
+type elementCommitter func(i int, from *Tree, val reflect.Value)
+
+// decodeElements decodes the n children of a collection node and commits them
+// in order. If parallel decoding is enabled, the elements are decoded
+// concurrently, but committed, warned about and failed on exactly like they
+// would be sequentially: only the elements before the first failing one are
+// committed and the error of the first failing element is returned.
+func (dec *Decoding) decodeElements(
+	target DecodeTarget,
+	children iter.Seq[*Tree],
+	n int,
+	decode elementDecoder,
+	commit elementCommitter,
+) error {
+	if !dec.parallel(target, n) {
+		i := 0
+		for from := range children {
+			val, err := decode(i, from, target)
+			if err != nil {
+				return err
+			}
+			commit(i, from, val)
+			i++
+		}
+		return nil
+	}
+
+	type result struct {
+		from  *Tree
+		val   reflect.Value
+		state *decodeState
+		err   error
+		panic any
+	}
+	results := make([]result, 0, n)
+	for from := range children {
+		results = append(results, result{from: from})
+	}
+
+	var next atomic.Int64
+	var failed atomic.Int64
+	failed.Store(int64(len(results)))
+
+	work := func() {
+		for {
+			i := int(next.Add(1) - 1)
+			if i >= len(results) || int64(i) > failed.Load() {
+				return
+			}
+
+			res := &results[i]
+			res.state = target.state.fork()
+			elem := target
+			elem.state = res.state
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						res.panic = r
+					}
+				}()
+				res.val, res.err = decode(i, res.from, elem)
+			}()
+
+			if res.err != nil || res.panic != nil {
+				for cur := failed.Load(); int64(i) < cur; cur = failed.Load() {
+					if failed.CompareAndSwap(cur, int64(i)) {
+						break
+					}
+				}
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+spawn:
+	for range len(results) - 1 {
+		select {
+		case target.state.sem <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-target.state.sem
+					wg.Done()
+				}()
+				work()
+			}()
+		default:
+			// All workers are busy, possibly with other collections. The
+			// remaining elements are decoded by the current goroutine.
+			break spawn
+		}
+	}
+	work()
+	wg.Wait()
+
+	for i := range results {
+		res := &results[i]
+		for _, w := range res.state.forked.warnings {
+			dec.report(target, w)
+		}
+		if res.panic != nil {
+			panic(res.panic)
+		}
+		if res.err != nil {
+			return res.err
+		}
+		commit(i, res.from, res.val)
+	}
+	return nil
+}
+
+// parallel reports whether the n elements of target should be decoded
+// concurrently.
+func (dec *Decoding) parallel(target DecodeTarget, n int) bool {
+	return dec.Parallelism > 1 &&
+		// The node count of concurrently decoded elements would depend on
+		// the scheduling, making MaxNodes errors non-deterministic.
+		dec.MaxNodes == 0 &&
+		n >= minParallelElements &&
+		target.state != nil &&
+		target.state.sem != nil
+}
+
+// fork returns the state of an element that is decoded concurrently with its
+// siblings. Warnings of the element are buffered until it is committed.
+func (s *decodeState) fork() *decodeState {
+	return &decodeState{ctx: s.ctx, sem: s.sem, forked: &forkState{parent: s}}
+}
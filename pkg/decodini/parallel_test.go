@@ -0,0 +1,113 @@
+package decodini
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode_Parallelism_KeepsOrder(t *testing.T) {
+	type item struct {
+		ID   int      `decodini:"id"`
+		Tags []string `decodini:"tags"`
+	}
+
+	a := assert.New(t)
+
+	from := make([]any, 1000)
+	want := make([]item, len(from))
+	for i := range from {
+		tags := make([]any, minParallelElements+i%3)
+		want[i] = item{ID: i, Tags: make([]string, len(tags))}
+		for j := range tags {
+			tags[j] = fmt.Sprint(i, j)
+			want[i].Tags[j] = fmt.Sprint(i, j)
+		}
+		from[i] = map[string]any{"id": i, "tags": tags}
+	}
+
+	dec := &Decoding{Parallelism: 4}
+	got, err := Decode[[]item](dec, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(want, got)
+
+	gotMap, err := Decode[map[string]int](dec, Encode(nil, map[string]any{
+		"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7, "h": 8,
+		"i": 9, "j": 10, "k": 11, "l": 12, "m": 13, "n": 14, "o": 15, "p": 16,
+	}))
+	a.NoError(err)
+	a.Len(gotMap, 16)
+	a.Equal(16, gotMap["p"])
+}
+
+func TestDecode_Parallelism_FirstError(t *testing.T) {
+	a := assert.New(t)
+
+	from := make([]any, 500)
+	for i := range from {
+		from[i] = i
+	}
+	from[100] = "foo"
+	from[400] = 1000
+
+	for range 20 {
+		got, err := Decode[[]int8](&Decoding{Parallelism: 8}, Encode(nil, from))
+		a.ErrorIs(err, ErrTypeMismatch)
+
+		var decErr *DecodeError
+		if a.ErrorAs(err, &decErr) {
+			a.Equal(Path{Index(100)}, decErr.Path())
+		}
+		if a.Len(got, len(from)) {
+			a.Equal(int8(99), got[99])
+			a.Zero(got[101])
+		}
+	}
+}
+
+func TestDecode_Parallelism_Warnings(t *testing.T) {
+	a := assert.New(t)
+
+	from := make([]any, 200)
+	for i := range from {
+		from[i] = float64(i) + 0.5
+	}
+
+	var want []Warning
+	_, err := Decode[[]int](&Decoding{
+		Warn: func(w Warning) { want = append(want, w) },
+	}, Encode(nil, from))
+	a.NoError(err)
+
+	var got []Warning
+	_, err = Decode[[]int](&Decoding{
+		Parallelism: 4,
+		Warn:        func(w Warning) { got = append(got, w) },
+	}, Encode(nil, from))
+	a.NoError(err)
+	a.Len(got, len(from))
+	a.Equal(want, got)
+}
+
+func TestDecode_Parallelism_Panic(t *testing.T) {
+	a := assert.New(t)
+
+	from := make([]any, 100)
+	for i := range from {
+		from[i] = i
+	}
+
+	dec := &Decoding{
+		Parallelism: 4,
+		Decoder: func(tr *Tree, target DecodeTarget) Decoder {
+			if tr.Name() == 50 {
+				panic("boom")
+			}
+			return nil
+		},
+	}
+	a.PanicsWithValue("boom", func() {
+		_, _ = Decode[[]int](dec, Encode(nil, from))
+	})
+}
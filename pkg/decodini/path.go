@@ -0,0 +1,350 @@
+package decodini
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathSegment is a single step of a Path. It is one of Field, Index, Key or
+// Wildcard.
+type PathSegment interface {
+	isPathSegment()
+}
+
+// Field is a path segment addressing a struct field by its resolved name.
+type Field string
+
+// Index is a path segment addressing an element of a slice or array.
+type Index int
+
+// Key is a path segment addressing an entry of a map.
+type Key struct {
+	Value any
+}
+
+// Wildcard is a path segment addressing any element of a slice or array, or
+// any entry of a map. It occurs in the paths of a PlanError, which refer to
+// types rather than to the nodes of a Tree.
+type Wildcard struct{}
+
+func (Field) isPathSegment()    {}
+func (Index) isPathSegment()    {}
+func (Key) isPathSegment()      {}
+func (Wildcard) isPathSegment() {}
+
+// Path is the sequence of segments leading from the root of a Tree to one of
+// its nodes. The root itself has an empty path.
+type Path []PathSegment
+
+// String returns the dot-separated representation of the path, e.g. "a.0.k".
+// Wildcards are represented by "*". The dotted form is lossy: it cannot
+// distinguish indices from keys and breaks on names containing dots. Use
+// JSONPath or JSONPointer if that matters.
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, seg := range p {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		switch seg := seg.(type) {
+		case Field:
+			sb.WriteString(string(seg))
+		case Index:
+			sb.WriteString(strconv.Itoa(int(seg)))
+		case Key:
+			sb.WriteString(fmt.Sprint(seg.Value))
+		case Wildcard:
+			sb.WriteByte('*')
+		}
+	}
+	return sb.String()
+}
+
+// JSONPath returns the JSONPath representation of the path, e.g.
+// `$.a[0]["k.x"]['my field']`. Fields with identifier-like names use the dot
+// notation, other fields are bracketed in single quotes. Map keys are always
+// bracketed in double quotes, so that ParseJSONPath tells them apart from
+// fields. Keys are rendered as strings, so non-string keys are parsed back as
+// their string representation. Wildcards are rendered as "[*]".
+func (p Path) JSONPath() string {
+	var sb strings.Builder
+	sb.WriteByte('$')
+	for _, seg := range p {
+		switch seg := seg.(type) {
+		case Field:
+			if isIdentifier(string(seg)) {
+				sb.WriteByte('.')
+				sb.WriteString(string(seg))
+			} else {
+				sb.WriteByte('[')
+				sb.WriteString(quoteField(string(seg)))
+				sb.WriteByte(']')
+			}
+		case Index:
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Itoa(int(seg)))
+			sb.WriteByte(']')
+		case Key:
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Quote(fmt.Sprint(seg.Value)))
+			sb.WriteByte(']')
+		case Wildcard:
+			sb.WriteString("[*]")
+		}
+	}
+	return sb.String()
+}
+
+// JSONPointer returns the RFC 6901 JSON Pointer representation of the path,
+// e.g. "/a/0/k.x". The root path is represented by the empty string. Like the
+// dotted form, JSON Pointers do not distinguish fields, keys and indices, and
+// wildcards are represented by the reference token "*".
+func (p Path) JSONPointer() string {
+	var sb strings.Builder
+	for _, seg := range p {
+		sb.WriteByte('/')
+		switch seg := seg.(type) {
+		case Field:
+			sb.WriteString(escapeJSONPointer(string(seg)))
+		case Index:
+			sb.WriteString(strconv.Itoa(int(seg)))
+		case Key:
+			sb.WriteString(escapeJSONPointer(fmt.Sprint(seg.Value)))
+		case Wildcard:
+			sb.WriteByte('*')
+		}
+	}
+	return sb.String()
+}
+
+// ParsePath parses the dotted representation returned by Path.String.
+// Segments consisting only of digits become an Index, all others a Field, as
+// the dotted form does not tell keys apart. Use JSONPath to preserve keys.
+func ParsePath(s string) (Path, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var p Path
+	for _, name := range strings.Split(s, ".") {
+		if name == "" {
+			return nil, fmt.Errorf("decodini: path %q: empty segment", s)
+		}
+		p = append(p, parseNameSegment(name))
+	}
+	return p, nil
+}
+
+// ParseJSONPointer parses an RFC 6901 JSON Pointer. Reference tokens that are
+// valid array indices become an Index, all others a Field, as JSON Pointers do
+// not tell keys apart. Use JSONPath to preserve keys.
+func ParseJSONPointer(s string) (Path, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("decodini: json pointer %q: must start with '/'", s)
+	}
+
+	var p Path
+	for _, token := range strings.Split(s[1:], "/") {
+		name, err := unescapeJSONPointer(token)
+		if err != nil {
+			return nil, fmt.Errorf("decodini: json pointer %q: %w", s, err)
+		}
+		p = append(p, parseNameSegment(name))
+	}
+	return p, nil
+}
+
+// ParseJSONPath parses the JSONPath subset returned by Path.JSONPath: a
+// leading '$' followed by `.name`, `['name']`, `[index]`, `["key"]` and `[*]`
+// segments. Dot and single-quoted names become a Field, bracketed integers an
+// Index, double-quoted names a Key and `[*]` a Wildcard.
+func ParseJSONPath(s string) (Path, error) {
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("decodini: json path %q: must start with '$'", s)
+	}
+
+	var p Path
+	rest := s[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("decodini: json path %q: empty segment", s)
+			}
+			p = append(p, Field(name))
+			rest = rest[end+1:]
+
+		case '[':
+			seg, n, err := parseJSONPathBracket(rest)
+			if err != nil {
+				return nil, fmt.Errorf("decodini: json path %q: %w", s, err)
+			}
+			p = append(p, seg)
+			rest = rest[n:]
+
+		default:
+			return nil, fmt.Errorf(
+				"decodini: json path %q: unexpected character %q", s, rest[0],
+			)
+		}
+	}
+	return p, nil
+}
+
+// parseJSONPathBracket parses a bracketed segment at the start of s and
+// returns it along with the number of consumed bytes.
+func parseJSONPathBracket(s string) (PathSegment, int, error) {
+	if len(s) > 1 && s[1] == '\'' {
+		name, n, err := unquoteField(s[1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		n++
+		if n >= len(s) || s[n] != ']' {
+			return nil, 0, errors.New("missing ']'")
+		}
+		return Field(name), n + 1, nil
+	}
+	if len(s) > 1 && s[1] == '"' {
+		prefix, err := strconv.QuotedPrefix(s[1:])
+		if err != nil {
+			return nil, 0, errors.New("malformed quoted key")
+		}
+		n := 1 + len(prefix)
+		if n >= len(s) || s[n] != ']' {
+			return nil, 0, errors.New("missing ']'")
+		}
+		key, err := strconv.Unquote(prefix)
+		if err != nil {
+			return nil, 0, errors.New("malformed quoted key")
+		}
+		return Key{Value: key}, n + 1, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end == -1 {
+		return nil, 0, errors.New("missing ']'")
+	}
+	if s[1:end] == "*" {
+		return Wildcard{}, end + 1, nil
+	}
+	i, err := strconv.Atoi(s[1:end])
+	if err != nil || i < 0 {
+		return nil, 0, fmt.Errorf("invalid index %q", s[1:end])
+	}
+	return Index(i), end + 1, nil
+}
+
+// quoteField returns name enclosed in single quotes, escaping single quotes
+// and backslashes with a backslash.
+func quoteField(name string) string {
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for i := range len(name) {
+		if name[i] == '\'' || name[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(name[i])
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// unquoteField parses the name quoted by quoteField at the start of s and
+// returns it along with the number of consumed bytes.
+func unquoteField(s string) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return sb.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, errors.New("malformed quoted field")
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return "", 0, errors.New("malformed quoted field")
+}
+
+func parseNameSegment(name string) PathSegment {
+	if i, ok := parseIndex(name); ok {
+		return Index(i)
+	}
+	return Field(name)
+}
+
+// parseIndex parses name as an RFC 6901 array index, i.e. "0" or a decimal
+// number without leading zeros.
+func parseIndex(name string) (int, bool) {
+	if name == "" || (len(name) > 1 && name[0] == '0') {
+		return 0, false
+	}
+	for _, r := range name {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(name)
+	return i, err == nil
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapeJSONPointer(name string) string {
+	return jsonPointerEscaper.Replace(name)
+}
+
+func unescapeJSONPointer(token string) (string, error) {
+	if !strings.Contains(token, "~") {
+		return token, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			sb.WriteByte(token[i])
+			continue
+		}
+		if i+1 >= len(token) {
+			return "", errors.New("dangling '~'")
+		}
+		switch token[i+1] {
+		case '0':
+			sb.WriteByte('~')
+		case '1':
+			sb.WriteByte('/')
+		default:
+			return "", fmt.Errorf("invalid escape '~%c'", token[i+1])
+		}
+		i++
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,114 @@
+package decodini
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath_Render(t *testing.T) {
+	a := assert.New(t)
+
+	p := Path{Field("a"), Index(0), Key{Value: "k.x"}, Field("my field")}
+
+	a.Equal("a.0.k.x.my field", p.String())
+	a.Equal(`$.a[0]["k.x"]['my field']`, p.JSONPath())
+	a.Equal("/a/0/k.x/my field", p.JSONPointer())
+
+	a.Equal("$", Path(nil).JSONPath())
+	a.Equal("", Path(nil).JSONPointer())
+}
+
+func TestPath_JSONPointer_Escaping(t *testing.T) {
+	a := assert.New(t)
+
+	p := Path{Key{Value: "a/b"}, Key{Value: "m~n"}}
+	a.Equal("/a~1b/m~0n", p.JSONPointer())
+
+	parsed, err := ParseJSONPointer("/a~1b/m~0n")
+	a.NoError(err)
+	a.Equal(Path{Field("a/b"), Field("m~n")}, parsed)
+
+	_, err = ParseJSONPointer("/a~2")
+	a.Error(err)
+
+	_, err = ParseJSONPointer("a")
+	a.Error(err)
+}
+
+func TestPath_ParseJSONPath_RoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	for _, p := range []Path{
+		{Field("a"), Index(0), Key{Value: "k.x"}, Key{Value: "0"}},
+		{Field("k.x"), Field("it's"), Field(`a\b`), Key{Value: "it's"}},
+		{Field("items"), Wildcard{}, Field("name")},
+	} {
+		parsed, err := ParseJSONPath(p.JSONPath())
+		a.NoError(err)
+		a.Equal(p, parsed)
+	}
+
+	for _, invalid := range []string{"a", "$[", `$["a"`, "$['a", `$['a\']`, "$[-1]", "$..a", "$a"} {
+		_, err := ParseJSONPath(invalid)
+		a.Error(err, invalid)
+	}
+}
+
+func TestPath_ParsePath(t *testing.T) {
+	a := assert.New(t)
+
+	parsed, err := ParsePath("a.0.b.01")
+	a.NoError(err)
+	a.Equal(Path{Field("a"), Index(0), Field("b"), Field("01")}, parsed)
+
+	_, err = ParsePath("a..b")
+	a.Error(err)
+}
+
+func TestTree_Path_DistinguishesKeysAndIndices(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{
+		"0": []string{"foo"},
+	})
+
+	leaf := tr.Child("0").Child(0)
+	a.Equal(Path{Key{Value: "0"}, Index(0)}, leaf.Path())
+	a.Equal(`$["0"][0]`, leaf.Path().JSONPath())
+}
+
+func TestTree_Path_NilKey(t *testing.T) {
+	a := assert.New(t)
+
+	tr := Encode(nil, map[any]any{nil: map[string]int{"x": 1}})
+
+	for child := range tr.Children() {
+		leaf := child.Child("x")
+		if a.NotNil(leaf) {
+			a.Equal(Path{Key{Value: nil}, Key{Value: "x"}}, leaf.Path())
+			a.Equal(`$["<nil>"]["x"]`, leaf.Path().JSONPath())
+		}
+	}
+}
+
+func TestDecodeError_Path(t *testing.T) {
+	type toStruct struct {
+		Items []int `decodini:"items"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{
+		"items": []any{1, "two"},
+	})
+
+	_, err := Decode[toStruct](nil, tr)
+
+	var decErr *DecodeError
+	if a.True(errors.As(err, &decErr)) {
+		a.Equal(Path{Key{Value: "items"}, Index(1)}, decErr.Path())
+		a.Equal("items.1", decErr.PathString())
+	}
+}
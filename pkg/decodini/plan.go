@@ -0,0 +1,178 @@
+package decodini
+
+import (
+	"reflect"
+	"sync"
+)
+
+// structPlan describes the fields of a struct type as seen through a given
+// struct tag. Plans are computed once per type and tag and are immutable
+// afterwards, which makes them safe to share between goroutines.
+type structPlan struct {
+	// fields holds the included fields in declaration order. Embedded structs
+	// are kept as a single field.
+	fields []fieldPlan
+
+	// flat holds the included fields in declaration order, with embedded
+	// structs expanded in place.
+	flat []fieldPlan
+
+	// byName maps the names of flat to their position in flat. If multiple
+	// fields resolve to the same name, the first one wins.
+	byName map[string]int
+
+	// names holds the names of all fields, including the names of embedded
+	// structs and the fields they contribute.
+	names []string
+
+	// nameSet holds the elements of names for constant time lookups.
+	nameSet map[string]bool
+
+	// indirect is true if an embedded struct is reached through a pointer,
+	// i.e. some fields of flat may be inaccessible for a given value.
+	indirect bool
+
+	// tuple maps the positions of the struct in tuple mode to the index of
+	// the field in flat, or -1 for positions without field.
+	tuple []int
+
+	// malformedIndex is the first field of flat whose index option is
+	// malformed, or nil, see tuplePositions.
+	malformedIndex *fieldPlan
+}
+
+// fieldPlan describes a single struct field.
+type fieldPlan struct {
+	name     string
+	index    []int
+	field    reflect.StructField
+	opts     tagOptions
+	embedded bool
+}
+
+type planKey struct {
+	typ reflect.Type
+	tag string
+}
+
+var planCache sync.Map // map[planKey]*structPlan
+
+// structPlanOf returns the plan of the struct type typ for the given tag.
+func structPlanOf(tag string, typ reflect.Type) *structPlan {
+	key := planKey{typ: typ, tag: tag}
+	if plan, ok := planCache.Load(key); ok {
+		return plan.(*structPlan)
+	}
+
+	plan := newStructPlan(tag, typ, map[reflect.Type]bool{})
+	actual, _ := planCache.LoadOrStore(key, plan)
+	return actual.(*structPlan)
+}
+
+// newStructPlan computes the plan of typ. Types in visiting are currently
+// being planned and are not expanded again, which stops infinite recursion
+// on self-embedding pointer types.
+func newStructPlan(
+	tag string,
+	typ reflect.Type,
+	visiting map[reflect.Type]bool,
+) *structPlan {
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	plan := &structPlan{
+		byName:  make(map[string]int),
+		nameSet: make(map[string]bool),
+	}
+	for i := range typ.NumField() {
+		sf := typ.Field(i)
+		if !includeStructField(tag, sf) {
+			continue
+		}
+
+		name, opts := lookupTag(tag, sf)
+		if name == "" {
+			name = sf.Name
+		}
+
+		embeddedType := sf.Type
+		if embeddedType.Kind() == reflect.Pointer {
+			embeddedType = embeddedType.Elem()
+		}
+
+		field := fieldPlan{
+			name:  name,
+			index: []int{i},
+			field: sf,
+			opts:  opts,
+			embedded: sf.Anonymous &&
+				embeddedType.Kind() == reflect.Struct &&
+				!visiting[embeddedType],
+		}
+		plan.fields = append(plan.fields, field)
+		plan.names = append(plan.names, name)
+
+		if !field.embedded {
+			plan.addFlat(field)
+			continue
+		}
+
+		sub := newStructPlan(tag, embeddedType, visiting)
+		plan.names = append(plan.names, sub.names...)
+		plan.indirect = plan.indirect ||
+			sub.indirect ||
+			sf.Type.Kind() == reflect.Pointer
+		for _, f := range sub.flat {
+			f.index = append([]int{i}, f.index...)
+			plan.addFlat(f)
+		}
+	}
+	for _, name := range plan.names {
+		plan.nameSet[name] = true
+	}
+	plan.tuple, plan.malformedIndex = tuplePositions(plan.flat)
+	return plan
+}
+
+// hasName reports whether name is the name of any field of the plan.
+func (p *structPlan) hasName(name string) bool {
+	return p.nameSet[name]
+}
+
+// hasOption reports whether any field of flat has one of the tag options.
+func (p *structPlan) hasOption(opts ...string) bool {
+	for i := range p.flat {
+		for _, opt := range opts {
+			if p.flat[i].opts.Has(opt) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// option returns the value of the tag option key of the field and whether it
+// is present. A nil field has no options.
+func (f *fieldPlan) option(key string) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	return f.opts.Get(key)
+}
+
+func (p *structPlan) addFlat(field fieldPlan) {
+	if _, exists := p.byName[field.name]; !exists {
+		p.byName[field.name] = len(p.flat)
+	}
+	p.flat = append(p.flat, field)
+}
+
+// fieldByIndex returns the nested field of the struct val at the given index
+// path. It reports false if the field is reached through a nil pointer.
+func fieldByIndex(val reflect.Value, index []int) (reflect.Value, bool) {
+	if len(index) == 1 {
+		return val.Field(index[0]), true
+	}
+	vf, err := val.FieldByIndexErr(index)
+	return vf, err == nil
+}
@@ -0,0 +1,99 @@
+package decodini
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructPlanOf_Cached(t *testing.T) {
+	type testStruct struct {
+		A string `decodini:"a"`
+	}
+
+	a := assert.New(t)
+
+	typ := reflect.TypeOf(testStruct{})
+
+	plans := make([]*structPlan, 8)
+	var wg sync.WaitGroup
+	for i := range plans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			plans[i] = structPlanOf("decodini", typ)
+		}()
+	}
+	wg.Wait()
+
+	for _, plan := range plans {
+		a.Same(plans[0], plan)
+	}
+	a.NotSame(plans[0], structPlanOf("json", typ))
+}
+
+func TestStructPlanOf_Flattened(t *testing.T) {
+	type (
+		Inner struct {
+			B int `decodini:"b"`
+			C int `decodini:"-"`
+		}
+		Outer struct {
+			A string `decodini:"a"`
+			Inner
+			D bool
+		}
+	)
+
+	a := assert.New(t)
+
+	plan := structPlanOf("decodini", reflect.TypeOf(Outer{}))
+
+	names := make([]string, len(plan.flat))
+	for i, field := range plan.flat {
+		names[i] = field.name
+	}
+	a.Equal([]string{"a", "b", "D"}, names)
+	a.Equal([]int{1, 0}, plan.flat[1].index)
+	a.Equal([]string{"a", "Inner", "b", "D"}, plan.names)
+	a.False(plan.indirect)
+}
+
+func TestEncode_NilEmbeddedPointer(t *testing.T) {
+	type (
+		Inner struct {
+			B int
+		}
+		Outer struct {
+			A string
+			*Inner
+		}
+	)
+
+	a := assert.New(t)
+
+	tr := Encode(nil, Outer{A: "foo"})
+	a.EqualValues(1, tr.NumChildren())
+	a.Nil(tr.Child("B"))
+
+	tr = Encode(nil, Outer{A: "foo", Inner: &Inner{B: 42}})
+	a.EqualValues(2, tr.NumChildren())
+	if child := tr.Child("B"); a.NotNil(child) {
+		a.Equal(42, child.Value().Interface())
+	}
+}
+
+func TestStructPlanOf_SelfEmbeddingPointer(t *testing.T) {
+	type Node struct {
+		*Node
+		Value int
+	}
+
+	a := assert.New(t)
+
+	plan := structPlanOf("decodini", reflect.TypeOf(Node{}))
+	a.Len(plan.fields, 2)
+	a.False(plan.fields[0].embedded)
+}
@@ -1,23 +1,22 @@
 package decodini
 
-import "reflect"
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+)
 
 func includeStructField(tag string, sf reflect.StructField) bool {
 	return sf.IsExported() && sf.Tag.Get(tag) != "-"
 }
 
-func structFieldName(tag string, sf reflect.StructField) string {
-	if tagName, hasTag := sf.Tag.Lookup(tag); hasTag {
-		return tagName
-	}
-	return sf.Name
-}
-
 func structFieldByName(
 	tag string,
 	val reflect.Value,
 	name string,
-) (reflect.StructField, reflect.Value) {
+) (*fieldPlan, reflect.Value) {
 	if val.Kind() == reflect.Pointer {
 		return structFieldByName(tag, val.Elem(), name)
 	}
@@ -26,28 +25,53 @@ func structFieldByName(
 		panic("decodini: cannot get struct field of non-struct")
 	}
 
-	typ := val.Type()
-	for i := range val.NumField() {
-		sf := typ.Field(i)
-		if !includeStructField(tag, sf) {
-			continue
-		}
-		vf := val.Field(i)
-
-		if sf.Anonymous {
-			sf, vf = structFieldByName(tag, vf, name)
-			if vf.IsValid() {
-				return sf, vf
-			}
-			continue
-		}
+	plan := structPlanOf(tag, val.Type())
+	i, ok := plan.byName[name]
+	if !ok {
+		return nil, reflect.Value{}
+	}
+
+	field := &plan.flat[i]
+	vf, ok := fieldByIndex(val, field.index)
+	if !ok {
+		return nil, reflect.Value{}
+	}
+	return field, vf
+}
 
-		if structFieldName(tag, sf) == name {
-			return sf, vf
+// structFieldNames returns the resolved names of the included fields of typ.
+// Anonymous embedded structs contribute both their own name and the names of
+// their fields.
+func structFieldNames(tag string, typ reflect.Type) []string {
+	if typ.Kind() == reflect.Pointer {
+		return structFieldNames(tag, typ.Elem())
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return structPlanOf(tag, typ).names
+}
+
+// missingNames returns the names that are not contained in exclude.
+func missingNames(names, exclude []string) []string {
+	var missing []string
+	for _, name := range names {
+		if !slices.Contains(exclude, name) {
+			missing = append(missing, name)
 		}
 	}
+	return missing
+}
 
-	return reflect.StructField{}, reflect.Value{}
+// mapKeyOf converts name into a key of the map key type typ. It reports false
+// if name cannot be used as such a key.
+func mapKeyOf(typ reflect.Type, name any) (reflect.Value, bool) {
+	key := reflect.ValueOf(name)
+	if !key.IsValid() || !key.Comparable() {
+		return reflect.Value{}, false
+	}
+	conv, err := convertKey(name, typ, false)
+	return conv, err == nil
 }
 
 func isPrimitive(kind reflect.Kind) bool {
@@ -59,6 +83,76 @@ func isPrimitive(kind reflect.Kind) bool {
 	}
 }
 
+func isInt(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUint(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	return isInt(kind) || isUint(kind) || isFloat(kind)
+}
+
+func isNaN(val reflect.Value) bool {
+	return isFloat(val.Kind()) && math.IsNaN(val.Float())
+}
+
+// overflows reports whether the numeric val cannot be represented by the
+// numeric type typ.
+func overflows(val reflect.Value, typ reflect.Type) bool {
+	zero := reflect.Zero(typ)
+	switch {
+	case isInt(typ.Kind()):
+		switch {
+		case isInt(val.Kind()):
+			return zero.OverflowInt(val.Int())
+		case isUint(val.Kind()):
+			u := val.Uint()
+			return u > math.MaxInt64 || zero.OverflowInt(int64(u))
+		default:
+			f := val.Float()
+			return math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 ||
+				zero.OverflowInt(int64(f))
+		}
+
+	case isUint(typ.Kind()):
+		switch {
+		case isInt(val.Kind()):
+			i := val.Int()
+			return i < 0 || zero.OverflowUint(uint64(i))
+		case isUint(val.Kind()):
+			return zero.OverflowUint(val.Uint())
+		default:
+			f := val.Float()
+			return math.IsNaN(f) || f < 0 || f >= math.MaxUint64 ||
+				zero.OverflowUint(uint64(f))
+		}
+
+	default:
+		if isFloat(val.Kind()) {
+			return zero.OverflowFloat(val.Float())
+		}
+		return false
+	}
+}
+
 func inferType(from *Tree, target DecodeTarget) reflect.Type {
 	if target.Value.Kind() == reflect.Interface {
 		return from.Value().Type()
@@ -91,19 +185,101 @@ func numStructFields(tag string, val reflect.Value) uint {
 	if val.Kind() != reflect.Struct {
 		return 0
 	}
-	typ := val.Type()
+
+	plan := structPlanOf(tag, val.Type())
+	if !plan.indirect {
+		return uint(len(plan.flat))
+	}
+
 	var n uint
-	for i := range val.NumField() {
-		sf := typ.Field(i)
-		if !includeStructField(tag, sf) {
-			continue
+	for _, field := range plan.flat {
+		if _, ok := fieldByIndex(val, field.index); ok {
+			n++
 		}
-		vf := val.Field(i)
-		if sf.Anonymous {
-			n += numStructFields(tag, vf)
-			continue
-		}
-		n++
 	}
 	return n
 }
+
+// sortedMapKeys returns the keys of the map val in the order defined by
+// Encoding.SortMapKeys.
+func sortedMapKeys(enc *Encoding, val reflect.Value) []reflect.Value {
+	keys := val.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return compareMapKeys(enc, a, b)
+	})
+	return keys
+}
+
+// compareMapKeys compares the map keys a and b. Keys of an interface type are
+// compared by their dynamic values. Strings, numbers and booleans are ordered
+// naturally and before other keys, with booleans first and strings last.
+func compareMapKeys(enc *Encoding, a, b reflect.Value) int {
+	if a.Kind() == reflect.Interface {
+		a, b = a.Elem(), b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() {
+		// nil interface keys come first.
+		return cmp.Compare(boolInt(a.IsValid()), boolInt(b.IsValid()))
+	}
+
+	ra, rb := keyRank(a), keyRank(b)
+	if ra != rb {
+		return cmp.Compare(ra, rb)
+	}
+
+	switch {
+	case a.Kind() == reflect.Bool:
+		return cmp.Compare(boolInt(a.Bool()), boolInt(b.Bool()))
+	case isInt(a.Kind()) && isInt(b.Kind()):
+		return cmp.Compare(a.Int(), b.Int())
+	case isUint(a.Kind()) && isUint(b.Kind()):
+		return cmp.Compare(a.Uint(), b.Uint())
+	case ra == keyRankNumber:
+		return cmp.Compare(toFloat(a), toFloat(b))
+	case a.Kind() == reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case enc.CompareMapKeys != nil:
+		return enc.CompareMapKeys(a.Interface(), b.Interface())
+	default:
+		return cmp.Compare(fmt.Sprintf("%#v", a), fmt.Sprintf("%#v", b))
+	}
+}
+
+const (
+	keyRankBool = iota
+	keyRankNumber
+	keyRankString
+	keyRankOther
+)
+
+func keyRank(val reflect.Value) int {
+	switch kind := val.Kind(); {
+	case kind == reflect.Bool:
+		return keyRankBool
+	case isNumeric(kind):
+		return keyRankNumber
+	case kind == reflect.String:
+		return keyRankString
+	default:
+		return keyRankOther
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// toFloat returns the numeric val as a float64.
+func toFloat(val reflect.Value) float64 {
+	switch {
+	case isInt(val.Kind()):
+		return float64(val.Int())
+	case isUint(val.Kind()):
+		return float64(val.Uint())
+	default:
+		return val.Float()
+	}
+}
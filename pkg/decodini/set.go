@@ -0,0 +1,139 @@
+package decodini
+
+import "reflect"
+
+// isSetType reports whether typ is a set-shaped map type, i.e. a map whose
+// values are empty structs or booleans, such as map[string]struct{} or
+// map[string]bool.
+func isSetType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Map {
+		return false
+	}
+	elem := typ.Elem()
+	return elem.Kind() == reflect.Bool ||
+		elem.Kind() == reflect.Struct && elem.NumField() == 0
+}
+
+// isSetSource reports whether the elements of the slice or array node are
+// decoded into the set-shaped map type typ as its keys rather than as its
+// values keyed by index. This is the case for maps with empty struct values,
+// and for boolean maps if the types of the elements convert to the key type.
+// The types of interface elements, and of the elements of tuples and encoded
+// sets, are checked one by one.
+func isSetSource(node *Tree, typ reflect.Type) bool {
+	if typ.Elem().Kind() == reflect.Struct {
+		return true
+	}
+	if kind := node.Value().Kind(); kind == reflect.Slice || kind == reflect.Array {
+		if elem := node.Value().Type().Elem(); elem.Kind() != reflect.Interface {
+			return convertsToKey(elem, typ.Key())
+		}
+	}
+	for child := range node.Children() {
+		if !child.IsNil() && !convertsToKey(child.Value().Type(), typ.Key()) {
+			return false
+		}
+	}
+	return true
+}
+
+// convertsToKey reports whether values of type from are decoded into the key
+// type key. Numbers are not considered to convert into strings.
+func convertsToKey(from, key reflect.Type) bool {
+	if from.Kind() == reflect.Interface || key.Kind() == reflect.Interface {
+		return true
+	}
+	if (isInt(from.Kind()) || isUint(from.Kind())) && key.Kind() == reflect.String {
+		return false
+	}
+	if from.Kind() == reflect.String && enumOf(key) != nil {
+		return true
+	}
+	return from.ConvertibleTo(key)
+}
+
+// setKeys returns the keys that are members of the set-shaped map val in
+// sorted order. Keys of boolean maps are members if their value is true.
+func setKeys(enc *Encoding, val reflect.Value) []reflect.Value {
+	keys := sortedMapKeys(enc, val)
+	if val.Type().Elem().Kind() != reflect.Bool {
+		return keys
+	}
+
+	members := keys[:0]
+	for _, key := range keys {
+		if val.MapIndex(key).Bool() {
+			members = append(members, key)
+		}
+	}
+	return members
+}
+
+// intoSetFromSlice decodes the elements of a slice or array as the keys of
+// the set-shaped map target, see isSetSource. Duplicate elements are ignored, unless
+// Decoding.RejectDuplicates is set.
+func (dec *Decoding) intoSetFromSlice(node *Tree, target DecodeTarget) error {
+	typ := target.Value.Type()
+
+	n := int(node.NumChildren())
+	keys := make([]reflect.Value, n)
+	froms := make([]*Tree, n)
+
+	err := dec.decodeElements(
+		target,
+		node.Children(),
+		n,
+		func(_ int, from *Tree, target DecodeTarget) (reflect.Value, error) {
+			key := reflect.New(typ.Key()).Elem()
+			if err := dec.into(from, target.sub(from.Name(), key)); err != nil {
+				return reflect.Value{}, err
+			}
+			if !key.Comparable() {
+				elemType := key.Type()
+				if key.Kind() == reflect.Interface {
+					elemType = key.Elem().Type()
+				}
+				return reflect.Value{}, newDecodeErrorf(
+					ErrTypeMismatch,
+					from,
+					target,
+					"cannot use %s as set element", elemType,
+				)
+			}
+			return key, nil
+		},
+		func(i int, from *Tree, key reflect.Value) {
+			keys[i], froms[i] = key, from
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if target.Value.IsNil() {
+		target.Value.Set(reflect.MakeMapWithSize(typ, n))
+	}
+	member := reflect.New(typ.Elem()).Elem()
+	if member.Kind() == reflect.Bool {
+		member.SetBool(true)
+	}
+
+	seen := make(map[any]int, n)
+	for i := range n {
+		key := keys[i].Interface()
+		if prev, ok := seen[key]; ok {
+			if !dec.RejectDuplicates {
+				continue
+			}
+			return newDecodeErrorf(
+				ErrDuplicateKey,
+				froms[i],
+				target,
+				"element %v duplicates element %v", froms[i].Name(), froms[prev].Name(),
+			)
+		}
+		seen[key] = i
+		target.Value.SetMapIndex(keys[i], member)
+	}
+	return nil
+}
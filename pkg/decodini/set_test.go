@@ -0,0 +1,123 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode_Slice_to_Set(t *testing.T) {
+	a := assert.New(t)
+
+	from := []string{"b", "a", "b"}
+
+	toStruct, err := Decode[map[string]struct{}](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(map[string]struct{}{"a": {}, "b": {}}, toStruct)
+
+	toBool, err := Decode[map[string]bool](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(map[string]bool{"a": true, "b": true}, toBool)
+
+	_, err = Decode[map[string]bool](&Decoding{RejectDuplicates: true}, Encode(nil, from))
+	a.ErrorIs(err, ErrDuplicateKey)
+	a.EqualError(err, "decodini: decode: failed at 2: element 2 duplicates element 0")
+
+	_, err = Decode[map[int]struct{}](nil, Encode(nil, []any{1, "x"}))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Index(1)}, decErr.Path())
+	}
+}
+
+func TestDecode_Slice_to_IndexMap_SetShaped(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := Transmute[map[int]bool](nil, []bool{true, false})
+	a.NoError(err)
+	a.Equal(map[int]bool{0: true, 1: false}, to)
+
+	to, err = Transmute[map[int]bool](nil, []any{false, true})
+	a.NoError(err)
+	a.Equal(map[int]bool{0: false, 1: true}, to)
+
+	set, err := Transmute[map[int]bool](nil, []int{3, 5})
+	a.NoError(err)
+	a.Equal(map[int]bool{3: true, 5: true}, set)
+
+	strs, err := Transmute[map[string]struct{}](nil, []any{"a", "b"})
+	a.NoError(err)
+	a.Equal(map[string]struct{}{"a": {}, "b": {}}, strs)
+}
+
+func TestDecode_Slice_to_Set_NotComparable(t *testing.T) {
+	type key struct {
+		X any
+	}
+
+	a := assert.New(t)
+
+	from := []any{map[string]any{"X": []int{1}}}
+	_, err := Decode[map[key]struct{}](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.ErrorContains(err, "cannot use decodini.key as set element")
+
+	_, err = Decode[map[any]struct{}](nil, Encode(nil, []any{[]int{1}}))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.ErrorContains(err, "cannot use []int as set element")
+}
+
+func TestEncode_Set(t *testing.T) {
+	a := assert.New(t)
+
+	enc := &Encoding{StructTag: "decodini", Sets: true}
+
+	tr := Encode(enc, map[string]bool{"c": true, "a": true, "b": false})
+	a.True(tr.IsSet())
+	a.Equal(uint(2), tr.NumChildren())
+	a.Equal("c", tr.Child(1).Value().Interface())
+	a.Nil(tr.Child("a"))
+
+	var keys []any
+	var paths []Path
+	for child := range tr.Children() {
+		keys = append(keys, child.Value().Interface())
+		paths = append(paths, child.Path())
+	}
+	a.Equal([]any{"a", "c"}, keys)
+	a.Equal([]Path{{Index(0)}, {Index(1)}}, paths)
+
+	a.False(Encode(nil, map[string]bool{"a": true}).IsSet())
+	a.False(Encode(enc, map[string]int{"a": 1}).IsSet())
+}
+
+func TestTransmute_Set_RoundTrip(t *testing.T) {
+	type tags struct {
+		Tags map[string]struct{} `decodini:"tags"`
+	}
+
+	a := assert.New(t)
+
+	tr, err := NewTransmutation(WithSets())
+	a.NoError(err)
+
+	from := tags{Tags: map[string]struct{}{"b": {}, "a": {}}}
+	generic, err := Transmute[map[string]any](&tr, from)
+	a.NoError(err)
+	a.Equal(map[string]any{"tags": []string{"a", "b"}}, generic)
+
+	back, err := Transmute[tags](&tr, generic)
+	a.NoError(err)
+	a.Equal(from, back)
+
+	tm, err := NewTransmuter[tags, struct {
+		Tags []string `decodini:"tags"`
+	}](&tr)
+	a.NoError(err)
+
+	list, err := tm.Run(from)
+	a.NoError(err)
+	a.Equal([]string{"a", "b"}, list.Tags)
+}
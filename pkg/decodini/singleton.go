@@ -0,0 +1,44 @@
+package decodini
+
+import "reflect"
+
+// intoSliceFromScalar decodes the scalar node into the slice target as its
+// only element, see Decoding.Singletons.
+func (dec *Decoding) intoSliceFromScalar(node *Tree, target DecodeTarget) error {
+	typ := inferType(node, target)
+	val := reflect.New(typ.Elem()).Elem()
+	if err := dec.into(node, target.sub(0, val)); err != nil {
+		return err
+	}
+
+	if target.Value.IsNil() || target.Value.Len() != 1 {
+		target.Value.Set(reflect.MakeSlice(typ, 1, 1))
+	}
+	target.Value.Index(0).Set(val)
+	return nil
+}
+
+// intoScalarFromSingleton decodes the only element of the slice or array node
+// into the scalar target, see Decoding.Singletons.
+func (dec *Decoding) intoScalarFromSingleton(node *Tree, target DecodeTarget) error {
+	if n := node.NumChildren(); n != 1 {
+		return newDecodeErrorf(
+			ErrTypeMismatch,
+			node,
+			target,
+			"cannot decode %s of %d elements into %s, expected a single element",
+			node.kind(), n, target.Value.Type(),
+		)
+	}
+	return dec.into(node.Child(0), target)
+}
+
+// isSingletonPair reports whether values of type from are decoded into values
+// of type into by wrapping or unwrapping them if Decoding.Singletons is set.
+func isSingletonPair(from, into reflect.Type) bool {
+	isList := func(typ reflect.Type) bool {
+		return typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array
+	}
+	return isPrimitive(from.Kind()) && into.Kind() == reflect.Slice ||
+		isList(from) && isPrimitive(into.Kind())
+}
@@ -0,0 +1,80 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode_Scalar_to_Slice(t *testing.T) {
+	type query struct {
+		Tags  []string `decodini:"tags"`
+		Ports []int    `decodini:"ports"`
+	}
+
+	a := assert.New(t)
+
+	dec := &Decoding{StructTag: "decodini", Singletons: true}
+	from := map[string]any{"tags": "prod", "ports": 80}
+
+	to, err := Decode[query](dec, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(query{Tags: []string{"prod"}, Ports: []int{80}}, to)
+
+	_, err = Decode[query](nil, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	bytes, err := Decode[[]byte](dec, Encode(nil, "ab"))
+	a.NoError(err)
+	a.Equal([]byte("ab"), bytes)
+}
+
+func TestDecode_Singleton_to_Scalar(t *testing.T) {
+	type query struct {
+		Page int    `decodini:"page"`
+		Sort string `decodini:"sort"`
+	}
+
+	a := assert.New(t)
+
+	dec := &Decoding{StructTag: "decodini", Singletons: true}
+
+	from := map[string][]string{"page": {"2"}, "sort": {"name"}}
+	_, err := Decode[query](dec, Encode(nil, from))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	to, err := Decode[query](dec, Encode(nil, map[string][]any{"page": {2}, "sort": {"name"}}))
+	a.NoError(err)
+	a.Equal(query{Page: 2, Sort: "name"}, to)
+
+	_, err = Decode[query](dec, Encode(nil, map[string][]any{"page": {1, 2}, "sort": {"name"}}))
+	a.ErrorIs(err, ErrTypeMismatch)
+	a.EqualError(
+		err,
+		"decodini: decode: failed at page: cannot decode slice of 2 elements into int, expected a single element",
+	)
+}
+
+func TestTransmuter_Singletons(t *testing.T) {
+	a := assert.New(t)
+
+	tr, err := NewTransmutation(WithSingletons())
+	a.NoError(err)
+
+	wrap, err := NewTransmuter[string, []string](&tr)
+	a.NoError(err)
+
+	list, err := wrap.Run("prod")
+	a.NoError(err)
+	a.Equal([]string{"prod"}, list)
+
+	unwrap, err := NewTransmuter[[]string, string](&tr)
+	a.NoError(err)
+
+	s, err := unwrap.Run([]string{"prod"})
+	a.NoError(err)
+	a.Equal("prod", s)
+
+	_, err = unwrap.Run([]string{"a", "b"})
+	a.ErrorIs(err, ErrTypeMismatch)
+}
@@ -0,0 +1,143 @@
+package decodini
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of suggestions attached to an error.
+const maxSuggestions = 3
+
+// suggest returns the candidates that are most similar to name, closest
+// first. Names are compared case-insensitively using the Levenshtein
+// distance, and candidates that are too far off are omitted.
+func suggest(name string, candidates []string) []string {
+	type scored struct {
+		name string
+		dist int
+	}
+
+	folded := strings.ToLower(name)
+	limit := max(2, len([]rune(name))/3)
+
+	var matches []scored
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		dist := levenshtein(folded, strings.ToLower(candidate))
+		if dist <= limit {
+			matches = append(matches, scored{name: candidate, dist: dist})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		if a.dist != b.dist {
+			return a.dist - b.dist
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+// Suggest returns the candidates that are most similar to name, closest first.
+//
+// Suggest only exists for code generated by decodini-gen, which attaches the
+// suggestions to its errors, and should not be called otherwise.
+func Suggest(name string, candidates []string) []string {
+	return suggest(name, candidates)
+}
+
+// withSuggestions appends the suggestions, if any, to the error message msg.
+func withSuggestions(msg string, suggestions []string) string {
+	if len(suggestions) == 0 {
+		return msg
+	}
+
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = strconv.Quote(s)
+	}
+	return fmt.Sprintf("%s (did you mean %s?)", msg, strings.Join(quoted, " or "))
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := range ra {
+		curr[0] = i + 1
+		for j := range rb {
+			cost := 1
+			if ra[i] == rb[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// childNames returns the string names of the children of node. Unlike
+// Children, it does not construct a node for every child.
+func childNames(node *Tree) []string {
+	val := node.Value()
+	switch val.Kind() {
+	case reflect.Struct:
+		plan := structPlanOf(node.enc.StructTag, val.Type())
+		names := make([]string, 0, len(plan.flat))
+		for _, field := range plan.flat {
+			if _, ok := fieldByIndex(val, field.index); ok {
+				names = append(names, field.name)
+			}
+		}
+		return names
+
+	case reflect.Map:
+		switch val.Type().Key().Kind() {
+		case reflect.String:
+		case reflect.Interface:
+			// Keys of maps decoded from e.g. YAML may hold strings.
+			var names []string
+			iter := val.MapRange()
+			for iter.Next() {
+				if key := iter.Key().Elem(); key.Kind() == reflect.String {
+					names = append(names, key.String())
+				}
+			}
+			return names
+		default:
+			return nil
+		}
+		names := make([]string, 0, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			names = append(names, iter.Key().String())
+		}
+		return names
+
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(0, levenshtein("", ""))
+	a.Equal(3, levenshtein("", "abc"))
+	a.Equal(1, levenshtein("timout", "timeout"))
+	a.Equal(3, levenshtein("kitten", "sitting"))
+	a.Equal(1, levenshtein("héllo", "hello"))
+}
+
+func TestSuggest(t *testing.T) {
+	a := assert.New(t)
+
+	candidates := []string{"hostname", "port", "timout", "unrelated"}
+
+	a.Equal([]string{"hostname"}, suggest("HostName", candidates))
+	a.Equal([]string{"timout"}, suggest("timeout", candidates))
+	a.Nil(suggest("database", candidates))
+}
+
+func TestDecode_UnmatchedField_Suggestions(t *testing.T) {
+	type toStruct struct {
+		HostName string
+		Timeout  int `decodini:"timeout"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{
+		"hostname": "localhost",
+		"timout":   10,
+	})
+
+	_, err := Decode[toStruct](nil, tr)
+	a.ErrorIs(err, ErrUnmatchedField)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal([]string{"hostname"}, decErr.Suggestions)
+		a.Contains(decErr.Error(), `did you mean "hostname"?`)
+	}
+}
+
+func TestDecode_Strict_UnknownField(t *testing.T) {
+	type toStruct struct {
+		Timeout int `decodini:"timeout"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{
+		"timeout": 10,
+		"timeuot": 20,
+	})
+
+	_, err := Decode[toStruct](nil, tr)
+	a.NoError(err)
+
+	_, err = Decode[toStruct](&Decoding{Strict: true}, tr)
+	a.ErrorIs(err, ErrUnknownField)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Key{Value: "timeuot"}}, decErr.Path())
+		// timeout is matched, so it is not suggested again.
+		a.Empty(decErr.Suggestions)
+	}
+}
+
+func TestDecode_Strict_SuggestsTargetFields(t *testing.T) {
+	type toStruct struct {
+		Timeout int `decodini:"timeout"`
+		Retries int `decodini:"retries"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{"timout": 10})
+
+	dec := &Decoding{
+		Strict: true,
+		Unmatched: func(*Tree, DecodeTarget) (*Tree, error) {
+			return nil, nil
+		},
+	}
+	_, err := Decode[toStruct](dec, tr)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal([]string{"timeout"}, decErr.Suggestions)
+	}
+}
+
+func TestDecode_Strict_EmbeddedStruct(t *testing.T) {
+	type (
+		Embedded struct {
+			A string `decodini:"a"`
+		}
+		Outer struct {
+			Embedded
+			B int `decodini:"b"`
+		}
+	)
+
+	a := assert.New(t)
+
+	tr := Encode(nil, map[string]any{"a": "foo", "b": 1})
+
+	to, err := Decode[Outer](&Decoding{Strict: true}, tr)
+	a.NoError(err)
+	a.Equal(Outer{Embedded: Embedded{A: "foo"}, B: 1}, to)
+}
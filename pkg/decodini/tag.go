@@ -0,0 +1,108 @@
+package decodini
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagOptions holds the options that follow the name in a struct tag, e.g.
+// `decodini:"timeout,deprecated='use timeout instead'"`. Values may be quoted
+// with single or double quotes to contain commas.
+type tagOptions map[string]string
+
+// parseTag splits a struct tag value into the name and its options.
+func parseTag(raw string) (string, tagOptions) {
+	parts := splitTag(raw)
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	var opts tagOptions
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		if opts == nil {
+			opts = make(tagOptions, len(parts)-1)
+		}
+		key, value, _ := strings.Cut(part, "=")
+		opts[strings.TrimSpace(key)] = unquoteTagValue(strings.TrimSpace(value))
+	}
+	return parts[0], opts
+}
+
+// lookupTag parses the tag of sf associated with the given key.
+func lookupTag(tag string, sf reflect.StructField) (string, tagOptions) {
+	raw, ok := sf.Tag.Lookup(tag)
+	if !ok {
+		return "", nil
+	}
+	return parseTag(raw)
+}
+
+// Has reports whether the option is present.
+func (o tagOptions) Has(key string) bool {
+	_, ok := o[key]
+	return ok
+}
+
+// Get returns the value of the option and whether it is present.
+func (o tagOptions) Get(key string) (string, bool) {
+	value, ok := o[key]
+	return value, ok
+}
+
+// splitTag splits raw at every comma that is not enclosed in quotes.
+func splitTag(raw string) []string {
+	var (
+		parts   []string
+		start   int
+		quote   byte
+		escaped bool
+	)
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quote != 0:
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ',':
+			parts = append(parts, raw[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, raw[start:])
+}
+
+// unquoteTagValue removes the surrounding quotes of value, if any, and
+// resolves backslash escapes inside of them.
+func unquoteTagValue(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	quote := value[0]
+	if (quote != '\'' && quote != '"') || value[len(value)-1] != quote {
+		return value
+	}
+
+	inner := value[1 : len(value)-1]
+	if !strings.Contains(inner, "\\") {
+		return inner
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			i++
+		}
+		sb.WriteByte(inner[i])
+	}
+	return sb.String()
+}
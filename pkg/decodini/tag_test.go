@@ -0,0 +1,38 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	a := assert.New(t)
+
+	name, opts := parseTag("timeout")
+	a.Equal("timeout", name)
+	a.Nil(opts)
+
+	name, opts = parseTag(`timeout,deprecated='use a, b or c',flag,sep=","`)
+	a.Equal("timeout", name)
+	a.Equal(tagOptions{
+		"deprecated": "use a, b or c",
+		"flag":       "",
+		"sep":        ",",
+	}, opts)
+
+	name, opts = parseTag(`,quote='it\'s'`)
+	a.Equal("", name)
+	a.Equal("it's", opts["quote"])
+}
+
+func TestStructFieldName_EmptyTagName(t *testing.T) {
+	type testStruct struct {
+		A string `decodini:",deprecated"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, testStruct{A: "foo"})
+	a.NotNil(tr.Child("A"))
+}
@@ -1,17 +1,19 @@
 package decodini
 
+import "context"
+
+// Transmutation configures the encoding and decoding of a transmutation. The
+// zero value uses the default configuration, and an empty struct tag defaults
+// to "decodini".
 type Transmutation struct {
-	Encoding *Encoding
-	Decoding *Decoding
+	Encoding Encoding
+	Decoding Decoding
 }
 
 // TransmuteInto encodes the given `from` value into a tree and decodes the tree
 // directly into the given `to` value.
 func TransmuteInto(tr *Transmutation, from, to any) error {
-	if tr == nil {
-		tr = new(Transmutation)
-	}
-	return DecodeInto(tr.Decoding, Encode(tr.Encoding, from), to)
+	return TransmuteIntoContext(context.Background(), tr, from, to)
 }
 
 // Transmute encodes the given `from` value into a tree, and decodes the tree
@@ -20,3 +22,26 @@ func Transmute[T any](tr *Transmutation, from any) (T, error) {
 	var to T
 	return to, TransmuteInto(tr, from, &to)
 }
+
+// TransmuteIntoContext is like TransmuteInto, but aborts decoding as soon as
+// ctx is done.
+func TransmuteIntoContext(ctx context.Context, tr *Transmutation, from, to any) error {
+	if tr == nil {
+		tr = new(Transmutation)
+	}
+	enc := &tr.Encoding
+	if enc.StructTag == "" {
+		// tr may be shared with other goroutines and must not be modified.
+		withTag := *enc
+		withTag.StructTag = defaultEncoding.StructTag
+		enc = &withTag
+	}
+	return DecodeIntoContext(ctx, &tr.Decoding, Encode(enc, from), to)
+}
+
+// TransmuteContext is like Transmute, but aborts decoding as soon as ctx is
+// done.
+func TransmuteContext[T any](ctx context.Context, tr *Transmutation, from any) (T, error) {
+	var to T
+	return to, TransmuteIntoContext(ctx, tr, from, &to)
+}
@@ -0,0 +1,84 @@
+package decodini
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransmuteContext_Canceled(t *testing.T) {
+	a := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	from := make([]int, 1000)
+	_, err := TransmuteContext[[]int](ctx, nil, from)
+	a.ErrorIs(err, context.Canceled)
+
+	var decErr *DecodeError
+	a.ErrorAs(err, &decErr)
+}
+
+func TestTransmuteContext_CanceledDuringTraversal(t *testing.T) {
+	a := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	visited := 0
+	tm := &Transmutation{
+		Decoding: Decoding{
+			Decoder: func(tr *Tree, target DecodeTarget) Decoder {
+				if target.Value.Kind() == reflect.Int {
+					visited++
+					if visited == 10 {
+						cancel()
+					}
+				}
+				return nil
+			},
+		},
+	}
+
+	from := make([]int, 10_000)
+	_, err := TransmuteContext[[]int](ctx, tm, from)
+	a.ErrorIs(err, context.Canceled)
+	a.Less(visited, len(from))
+}
+
+func TestTransmuteContext_DecoderReadsValues(t *testing.T) {
+	type tenantKey struct{}
+
+	a := assert.New(t)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	tm := &Transmutation{
+		Decoding: Decoding{
+			Decoder: func(tr *Tree, target DecodeTarget) Decoder {
+				if target.Name != "tenant" {
+					return nil
+				}
+				return func(tr *Tree, target DecodeTarget) error {
+					tenant := target.Context().Value(tenantKey{}).(string)
+					target.Value.SetString(tenant)
+					return nil
+				}
+			},
+		},
+	}
+
+	to, err := TransmuteContext[map[string]string](ctx, tm, map[string]string{
+		"tenant": "",
+	})
+	a.NoError(err)
+	a.Equal(map[string]string{"tenant": "acme"}, to)
+}
+
+func TestDecodeTarget_Context_DefaultsToBackground(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(context.Background(), DecodeTarget{}.Context())
+}
@@ -0,0 +1,600 @@
+package decodini
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Transmuter converts values of type From into values of type To. The mapping
+// between both types is planned and validated once by NewTransmuter, so that
+// Run only has to move the values. A Transmuter is safe for concurrent use.
+type Transmuter[From, To any] struct {
+	enc Encoding
+	dec Decoding
+
+	// conv is the compiled conversion, or nil if every run has to use the
+	// reflective Tree-based decoding.
+	conv *converter
+}
+
+// PlanError reports a problem in the mapping between two types that was
+// detected while constructing a Transmuter.
+type PlanError struct {
+	// Path is the location of the problem in the target type, e.g.
+	// `$.items[*].name` in JSONPath notation. The elements of slices and
+	// arrays and the values of maps are denoted by a Wildcard.
+	Path Path
+
+	From reflect.Type
+	Into reflect.Type
+	Err  error
+
+	// Kind is one of the Err* error kinds declared by this package.
+	Kind error
+
+	// Suggestions holds the names that were most likely meant instead of the
+	// unmatched or unknown one, closest first.
+	Suggestions []string
+}
+
+var _ error = (*PlanError)(nil)
+
+// Unwrap returns the underlying error.
+func (e *PlanError) Unwrap() error { return e.Err }
+
+// Is reports whether target is the kind of this error.
+func (e *PlanError) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+// Error returns the error message.
+func (e *PlanError) Error() string {
+	path := e.Path.String()
+	if path == "" {
+		path = "<root>"
+	}
+	msg := fmt.Sprintf("decodini: plan: failed at %s: %s", path, e.Err)
+	return withSuggestions(msg, e.Suggestions)
+}
+
+// NewTransmuter plans the conversion from From to To using the given
+// transmutation. Unmatched and incompatible fields are reported as a
+// *PlanError.
+//
+// The plan is executed without building a Tree whenever possible. Custom
+// decoders, warnings and resource limits depend on the individual values, so
+// transmutations configuring any of them always use the reflective decoding.
+// The same applies to recursive types, whose values may contain pointer
+// cycles that are only detected by the reflective decoding.
+func NewTransmuter[From, To any](tr *Transmutation) (*Transmuter[From, To], error) {
+	t := new(Transmuter[From, To])
+	if tr != nil {
+		t.enc, t.dec = tr.Encoding, tr.Decoding
+	}
+	if t.enc.StructTag == "" {
+		t.enc.StructTag = defaultEncoding.StructTag
+	}
+	if t.dec.StructTag == "" {
+		t.dec.StructTag = defaultDecoding.StructTag
+	}
+
+	c := &compiler{
+		enc:       &t.enc,
+		dec:       &t.dec,
+		compiling: make(map[typePair]*converter),
+	}
+	conv, err := c.compile(
+		nil,
+		reflect.TypeFor[From](),
+		reflect.TypeFor[To](),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.dec.needsReflection() && !c.recursive {
+		t.conv = conv
+	}
+	return t, nil
+}
+
+// Run converts from into a new value of type To.
+func (t *Transmuter[From, To]) Run(from From) (To, error) {
+	return t.RunContext(context.Background(), from)
+}
+
+// RunContext is like Run, but the reflective decoding of the values aborts as
+// soon as ctx is done.
+func (t *Transmuter[From, To]) RunContext(ctx context.Context, from From) (To, error) {
+	var to To
+	if t.conv != nil {
+		src := reflect.ValueOf(&from).Elem()
+		dst := reflect.ValueOf(&to).Elem()
+		if t.conv.fn(src, dst, t.dec.newState(ctx)) {
+			return to, nil
+		}
+		var zero To
+		to = zero
+	}
+
+	// The compiled conversion bailed out, either because it cannot handle the
+	// values or because decoding fails. The reflective decoding handles the
+	// former and reports the latter with the precise path.
+	return to, DecodeIntoContext(ctx, &t.dec, Encode(&t.enc, from), &to)
+}
+
+// needsReflection reports whether dec is configured with options that depend
+// on the individual values of a decoding run.
+func (dec *Decoding) needsReflection() bool {
+	return dec.Decoder != nil ||
+		dec.Warn != nil ||
+		dec.MaxDepth > 0 ||
+		dec.MaxElements > 0 ||
+		dec.MaxNodes > 0 ||
+		dec.MaxStringLength > 0
+}
+
+// convertFunc moves src into dst. It returns false if the conversion cannot
+// be completed, in which case dst may be partially written.
+type convertFunc func(src, dst reflect.Value, state *decodeState) bool
+
+// converter wraps a convertFunc, so that recursive types can refer to a
+// converter before it is fully compiled.
+type converter struct {
+	fn convertFunc
+}
+
+type typePair struct {
+	from, into reflect.Type
+}
+
+type compiler struct {
+	enc       *Encoding
+	dec       *Decoding
+	compiling map[typePair]*converter
+
+	// recursive is true if a type refers to itself, so that its values may
+	// contain pointer cycles.
+	recursive bool
+}
+
+func (c *compiler) compile(path Path, from, into reflect.Type) (*converter, error) {
+	pair := typePair{from: from, into: into}
+	if conv, ok := c.compiling[pair]; ok {
+		if conv.fn == nil {
+			c.recursive = true
+		}
+		return conv, nil
+	}
+
+	conv := new(converter)
+	c.compiling[pair] = conv
+
+	fn, err := c.compileFunc(path, from, into)
+	if err != nil {
+		return nil, err
+	}
+	conv.fn = fn
+	return conv, nil
+}
+
+func (c *compiler) compileFunc(path Path, from, into reflect.Type) (convertFunc, error) {
+	switch {
+	case into.Kind() == reflect.Pointer:
+		return c.compileIntoPointer(path, from, into)
+	case from.Kind() == reflect.Pointer:
+		return c.compileFromPointer(path, from, into)
+	case from.Kind() == reflect.Interface || into.Kind() == reflect.Interface:
+		return c.dynamic(), nil
+	case hasTuple(c.enc.StructTag, from) || hasTuple(c.dec.StructTag, into):
+		return c.dynamic(), nil
+	case hasKeyBy(c.dec.StructTag, into) || hasEntries(c.enc.StructTag, from):
+		return c.dynamic(), nil
+	case c.enc.Entries != (EntryNames{}) && from.Kind() == reflect.Map,
+		c.dec.Entries != (EntryNames{}) &&
+			(into.Kind() == reflect.Map || into.Kind() == reflect.Slice):
+		return c.dynamic(), nil
+	case c.enc.Sets && isSetType(from):
+		return c.dynamic(), nil
+	case c.dec.Singletons && isSingletonPair(from, into):
+		return c.dynamic(), nil
+	case hasByteEncodings(c.enc.StructTag, from) || hasByteEncodings(c.dec.StructTag, into),
+		c.enc.Bytes != BytesRaw && isByteSequence(from),
+		c.dec.Bytes != BytesRaw && (isByteSequence(from) || isByteSequence(into)),
+		into.Kind() == reflect.Array && isByteSequence(into):
+		return c.dynamic(), nil
+	case hasDelimited(c.enc.StructTag, from) || hasDelimited(c.dec.StructTag, into),
+		c.dec.Separator != "" && from.Kind() == reflect.String && into.Kind() == reflect.Slice:
+		return c.dynamic(), nil
+	case enumOf(from) != nil || enumOf(into) != nil:
+		return c.dynamic(), nil
+	case isPrimitive(into.Kind()):
+		return c.compileScalar(path, from, into)
+	}
+
+	switch into.Kind() {
+	case reflect.Struct:
+		return c.compileStruct(path, from, into)
+	case reflect.Slice:
+		return c.compileSlice(path, from, into)
+	case reflect.Map:
+		return c.compileMap(path, from, into)
+	default:
+		return nil, newPlanErrorf(
+			ErrUnsupportedKind,
+			path,
+			from,
+			into,
+			"cannot decode into %s", into,
+		)
+	}
+}
+
+func (c *compiler) compileIntoPointer(
+	path Path,
+	from, into reflect.Type,
+) (convertFunc, error) {
+	elem, err := c.compile(path, derefType(from), into.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(src, dst reflect.Value, state *decodeState) bool {
+		for src.Kind() == reflect.Pointer || src.Kind() == reflect.Interface {
+			if src.IsNil() {
+				dst.SetZero()
+				return true
+			}
+			src = src.Elem()
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(into.Elem()))
+		}
+		return elem.fn(src, dst.Elem(), state)
+	}, nil
+}
+
+func (c *compiler) compileFromPointer(
+	path Path,
+	from, into reflect.Type,
+) (convertFunc, error) {
+	elem, err := c.compile(path, from.Elem(), into)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(src, dst reflect.Value, state *decodeState) bool {
+		if src.IsNil() {
+			dst.SetZero()
+			return true
+		}
+		return elem.fn(src.Elem(), dst, state)
+	}, nil
+}
+
+func (c *compiler) compileScalar(
+	path Path,
+	from, into reflect.Type,
+) (convertFunc, error) {
+	switch {
+	case from.AssignableTo(into):
+		return func(src, dst reflect.Value, state *decodeState) bool {
+			dst.Set(src)
+			return true
+		}, nil
+
+	case isNumeric(from.Kind()) && isNumeric(into.Kind()):
+		return func(src, dst reflect.Value, state *decodeState) bool {
+			if overflows(src, into) {
+				return false
+			}
+			dst.Set(src.Convert(into))
+			return true
+		}, nil
+
+	case from.Kind() == into.Kind() && from.ConvertibleTo(into):
+		return func(src, dst reflect.Value, state *decodeState) bool {
+			dst.Set(src.Convert(into))
+			return true
+		}, nil
+
+	case into.Kind() == reflect.String &&
+		(from.Kind() == reflect.Slice || from.Kind() == reflect.Array):
+		return c.dynamic(), nil
+
+	case !isPrimitive(from.Kind()):
+		return nil, newPlanErrorf(
+			ErrTypeMismatch,
+			path,
+			from,
+			into,
+			"leaf decoder can't decode non-leaf %s", from.Kind(),
+		)
+
+	default:
+		return nil, newPlanErrorf(
+			ErrTypeMismatch,
+			path,
+			from,
+			into,
+			"cannot decode %s into %s", from, into,
+		)
+	}
+}
+
+func (c *compiler) compileStruct(
+	path Path,
+	from, into reflect.Type,
+) (convertFunc, error) {
+	return c.compileStructFields(path, from, into, false)
+}
+
+// compileStructFields compiles the conversion of the fields of into. If
+// inline is true, into is an embedded struct decoded from the same source as
+// its parent.
+func (c *compiler) compileStructFields(
+	path Path,
+	from, into reflect.Type,
+	inline bool,
+) (convertFunc, error) {
+	switch from.Kind() {
+	case reflect.Map:
+		return c.dynamic(), nil
+	case reflect.Struct:
+	default:
+		return nil, newPlanErrorf(
+			ErrTypeMismatch,
+			path,
+			from,
+			into,
+			"cannot decode %s into struct", from.Kind(),
+		)
+	}
+
+	type fieldConv struct {
+		from, into []int
+		conv       *converter
+	}
+
+	srcPlan := structPlanOf(c.enc.StructTag, from)
+	dstPlan := structPlanOf(c.dec.StructTag, into)
+
+	var fields []fieldConv
+	for _, field := range dstPlan.fields {
+		fieldPath := appendPath(path, Field(field.name))
+
+		i, ok := srcPlan.byName[field.name]
+		if field.embedded && !ok {
+			// Like the reflective decoding, the embedded struct is decoded
+			// inline unless the source has a field named after it.
+			fn, err := c.compileEmbedded(path, from, field.field.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, fieldConv{
+				into: field.index,
+				conv: &converter{fn: fn},
+			})
+			continue
+		}
+
+		if !ok {
+			if c.dec.Unmatched != nil {
+				// The outcome of the hook depends on the values.
+				return c.dynamic(), nil
+			}
+			err := newPlanErrorf(
+				ErrUnmatchedField,
+				fieldPath,
+				nil,
+				into,
+				"struct field %s is unmatched in source type %s", field.name, from,
+			)
+			err.Suggestions = suggest(field.name, missingNames(srcPlan.names, dstPlan.names))
+			return nil, err
+		}
+
+		srcField := srcPlan.flat[i]
+		conv, err := c.compile(fieldPath, srcField.field.Type, field.field.Type)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, fieldConv{
+			from: srcField.index,
+			into: field.index,
+			conv: conv,
+		})
+	}
+
+	if c.dec.Strict && !inline {
+		for _, field := range srcPlan.flat {
+			if dstPlan.hasName(field.name) {
+				continue
+			}
+			err := newPlanErrorf(
+				ErrUnknownField,
+				appendPath(path, Field(field.name)),
+				field.field.Type,
+				nil,
+				"source field %s does not match any struct field of %s", field.name, into,
+			)
+			err.Suggestions = suggest(field.name, missingNames(dstPlan.names, srcPlan.names))
+			return nil, err
+		}
+	}
+
+	return func(src, dst reflect.Value, state *decodeState) bool {
+		for _, f := range fields {
+			sf := src
+			if f.from != nil {
+				var ok bool
+				if sf, ok = fieldByIndex(src, f.from); !ok {
+					return false
+				}
+			}
+			if !f.conv.fn(sf, dst.Field(f.into[0]), state) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// compileEmbedded compiles the inline conversion of from into the embedded
+// struct (or struct pointer) type into.
+func (c *compiler) compileEmbedded(
+	path Path,
+	from, into reflect.Type,
+) (convertFunc, error) {
+	if into.Kind() != reflect.Pointer {
+		return c.compileStructFields(path, from, into, true)
+	}
+
+	elem, err := c.compileStructFields(path, from, into.Elem(), true)
+	if err != nil {
+		return nil, err
+	}
+	return func(src, dst reflect.Value, state *decodeState) bool {
+		if dst.IsNil() {
+			dst.Set(reflect.New(into.Elem()))
+		}
+		return elem(src, dst.Elem(), state)
+	}, nil
+}
+
+func (c *compiler) compileSlice(
+	path Path,
+	from, into reflect.Type,
+) (convertFunc, error) {
+	switch from.Kind() {
+	case reflect.String, reflect.Map:
+		return c.dynamic(), nil
+	case reflect.Slice, reflect.Array:
+	default:
+		return nil, newPlanErrorf(
+			ErrTypeMismatch,
+			path,
+			from,
+			into,
+			"cannot decode %s into slice", from.Kind(),
+		)
+	}
+
+	elem, err := c.compile(appendPath(path, Wildcard{}), from.Elem(), into.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(src, dst reflect.Value, state *decodeState) bool {
+		n := src.Len()
+		if dst.IsNil() {
+			dst.Set(reflect.MakeSlice(into, n, n))
+		} else if dst.Len() < n {
+			return false
+		}
+		for i := range n {
+			if !elem.fn(src.Index(i), dst.Index(i), state) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (c *compiler) compileMap(
+	path Path,
+	from, into reflect.Type,
+) (convertFunc, error) {
+	switch from.Kind() {
+	case reflect.Struct:
+		return c.dynamic(), nil
+	case reflect.Slice, reflect.Array:
+		key := into.Key().Kind()
+		if !isSetType(into) && !isInt(key) && !isUint(key) && key != reflect.String {
+			return nil, newPlanErrorf(
+				ErrTypeMismatch,
+				path,
+				from,
+				into,
+				"cannot use slice indices as map key of type %s", into.Key(),
+			)
+		}
+		return c.dynamic(), nil
+	case reflect.Map:
+	default:
+		return nil, newPlanErrorf(
+			ErrTypeMismatch,
+			path,
+			from,
+			into,
+			"cannot decode %s into map", from.Kind(),
+		)
+	}
+
+	if !from.Key().AssignableTo(into.Key()) {
+		return c.dynamic(), nil
+	}
+
+	elem, err := c.compile(appendPath(path, Wildcard{}), from.Elem(), into.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(src, dst reflect.Value, state *decodeState) bool {
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(into, src.Len()))
+		}
+		val := reflect.New(into.Elem()).Elem()
+		iter := src.MapRange()
+		for iter.Next() {
+			val.SetZero()
+			if !elem.fn(iter.Value(), val, state) {
+				return false
+			}
+			dst.SetMapIndex(iter.Key(), val)
+		}
+		return true
+	}, nil
+}
+
+// dynamic returns a conversion that falls back to the Tree-based decoding,
+// used where the mapping depends on the values rather than their types. The
+// decoding shares the state of the run, i.e. its context and goroutines.
+func (c *compiler) dynamic() convertFunc {
+	enc, dec := c.enc, c.dec
+	return func(src, dst reflect.Value, state *decodeState) bool {
+		node := Encode(enc, src)
+		target := DecodeTarget{Value: dst, state: state}
+		return dec.into(node, target) == nil
+	}
+}
+
+func newPlanErrorf(
+	kind error,
+	path Path,
+	from, into reflect.Type,
+	format string,
+	args ...any,
+) *PlanError {
+	return &PlanError{
+		Path: path,
+		From: from,
+		Into: into,
+		Err:  fmt.Errorf(format, args...),
+		Kind: kind,
+	}
+}
+
+// appendPath returns path followed by seg. Unlike append, it never writes to
+// the array of path, which is shared by the paths of sibling types.
+func appendPath(path Path, seg PathSegment) Path {
+	return append(path[:len(path):len(path)], seg)
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,320 @@
+package decodini
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransmuter_Struct_to_Struct(t *testing.T) {
+	type (
+		fromAddress struct {
+			City string `decodini:"city"`
+		}
+		fromUser struct {
+			Name      string `decodini:"name"`
+			Age       int32
+			Tags      []string
+			Labels    map[string]int
+			Address   *fromAddress `decodini:"address"`
+			Secondary *fromAddress
+			ignored   bool
+		}
+		toAddress struct {
+			Town string `decodini:"city"`
+		}
+		toUser struct {
+			Name      string `decodini:"name"`
+			Age       int64
+			Tags      []string
+			Labels    map[string]*int
+			Address   toAddress `decodini:"address"`
+			Secondary *toAddress
+		}
+	)
+
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[fromUser, toUser](nil)
+	a.NoError(err)
+
+	from := fromUser{
+		Name:    "alice",
+		Age:     30,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]int{"x": 1},
+		Address: &fromAddress{City: "Vienna"},
+	}
+
+	to, err := tm.Run(from)
+	a.NoError(err)
+	a.Equal(toUser{
+		Name:    "alice",
+		Age:     30,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]*int{"x": ptr(1)},
+		Address: toAddress{Town: "Vienna"},
+	}, to)
+
+	expected, err := Transmute[toUser](nil, from)
+	a.NoError(err)
+	a.Equal(expected, to)
+}
+
+func TestTransmuter_UnmatchedField(t *testing.T) {
+	type (
+		fromStruct struct {
+			HostName string `decodini:"hostname"`
+		}
+		toStruct struct {
+			Nested struct {
+				Host string `decodini:"host"`
+			} `decodini:"nested"`
+		}
+	)
+
+	a := assert.New(t)
+
+	_, err := NewTransmuter[fromStruct, toStruct](nil)
+	a.ErrorIs(err, ErrUnmatchedField)
+
+	type toFlat struct {
+		HostName string `decodini:"hostnme"`
+	}
+	_, err = NewTransmuter[fromStruct, toFlat](nil)
+	a.ErrorIs(err, ErrUnmatchedField)
+
+	var planErr *PlanError
+	if a.ErrorAs(err, &planErr) {
+		a.Equal(Path{Field("hostnme")}, planErr.Path)
+		a.Equal([]string{"hostname"}, planErr.Suggestions)
+	}
+}
+
+func TestTransmuter_IncompatibleField(t *testing.T) {
+	type (
+		fromStruct struct {
+			Items []string `decodini:"items"`
+		}
+		toStruct struct {
+			Items []int `decodini:"items"`
+		}
+	)
+
+	a := assert.New(t)
+
+	_, err := NewTransmuter[fromStruct, toStruct](nil)
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var planErr *PlanError
+	if a.ErrorAs(err, &planErr) {
+		a.Equal(Path{Field("items"), Wildcard{}}, planErr.Path)
+		a.Equal("$.items[*]", planErr.Path.JSONPath())
+		a.Equal("/items/*", planErr.Path.JSONPointer())
+	}
+	a.EqualError(err, "decodini: plan: failed at items.*: cannot decode string into int")
+}
+
+func TestTransmuter_RunContext_Dynamic(t *testing.T) {
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[[]any, []int](&Transmutation{
+		Decoding: Decoding{Parallelism: 4},
+	})
+	a.NoError(err)
+
+	from := make([]any, 1000)
+	for i := range from {
+		from[i] = i
+	}
+	to, err := tm.Run(from)
+	a.NoError(err)
+	a.Len(to, len(from))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tm.RunContext(ctx, from)
+	a.ErrorIs(err, context.Canceled)
+}
+
+func TestTransmuter_Strict(t *testing.T) {
+	type (
+		fromStruct struct {
+			A int
+			B int
+		}
+		toStruct struct {
+			A int
+		}
+	)
+
+	a := assert.New(t)
+
+	_, err := NewTransmuter[fromStruct, toStruct](nil)
+	a.NoError(err)
+
+	_, err = NewTransmuter[fromStruct, toStruct](&Transmutation{
+		Decoding: Decoding{Strict: true},
+	})
+	a.ErrorIs(err, ErrUnknownField)
+}
+
+func TestTransmuter_RuntimeErrorHasPath(t *testing.T) {
+	type (
+		fromStruct struct {
+			Values []int `decodini:"values"`
+		}
+		toStruct struct {
+			Values []int8 `decodini:"values"`
+		}
+	)
+
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[fromStruct, toStruct](nil)
+	a.NoError(err)
+
+	_, err = tm.Run(fromStruct{Values: []int{1, 300}})
+	a.ErrorIs(err, ErrOverflow)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Field("values"), Index(1)}, decErr.Path())
+	}
+}
+
+func TestTransmuter_Map_to_Struct(t *testing.T) {
+	type toStruct struct {
+		A string `decodini:"a"`
+		B int
+	}
+
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[map[string]any, toStruct](nil)
+	a.NoError(err)
+
+	to, err := tm.Run(map[string]any{"a": "foo", "B": 42})
+	a.NoError(err)
+	a.Equal(toStruct{A: "foo", B: 42}, to)
+
+	_, err = tm.Run(map[string]any{"a": "foo"})
+	a.ErrorIs(err, ErrUnmatchedField)
+}
+
+func TestTransmuter_EmbeddedStruct(t *testing.T) {
+	type (
+		Embedded struct {
+			A string `decodini:"a"`
+		}
+		fromStruct struct {
+			A string `decodini:"a"`
+			B int
+		}
+		toStruct struct {
+			*Embedded
+			B int
+		}
+	)
+
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[fromStruct, toStruct](&Transmutation{
+		Decoding: Decoding{Strict: true},
+	})
+	a.NoError(err)
+
+	to, err := tm.Run(fromStruct{A: "foo", B: 1})
+	a.NoError(err)
+	a.Equal(toStruct{Embedded: &Embedded{A: "foo"}, B: 1}, to)
+}
+
+func TestTransmuter_EmbeddedStruct_NamedSource(t *testing.T) {
+	type (
+		Base struct {
+			ID int
+		}
+		fromStruct struct {
+			Base Base
+			Name string
+		}
+		toStruct struct {
+			Base
+			Name string
+		}
+	)
+
+	a := assert.New(t)
+
+	from := fromStruct{Base: Base{ID: 1}, Name: "x"}
+
+	want, err := Transmute[toStruct](nil, from)
+	a.NoError(err)
+	a.Equal(toStruct{Base: Base{ID: 1}, Name: "x"}, want)
+
+	tm, err := NewTransmuter[fromStruct, toStruct](nil)
+	a.NoError(err)
+
+	to, err := tm.Run(from)
+	a.NoError(err)
+	a.Equal(want, to)
+}
+
+func TestTransmuter_RecursiveType(t *testing.T) {
+	type node struct {
+		Value int
+		Next  *node
+	}
+
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[node, node](nil)
+	a.NoError(err)
+
+	from := node{Value: 1, Next: &node{Value: 2}}
+	to, err := tm.Run(from)
+	a.NoError(err)
+	a.Equal(from, to)
+	a.NotSame(from.Next, to.Next)
+}
+
+func TestTransmuter_Concurrent(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[user, map[string]any](nil)
+	a.NoError(err)
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			to, err := tm.Run(user{Name: "alice", Tags: []string{"a"}})
+			a.NoError(err)
+			a.Equal(map[string]any{"Name": "alice", "Tags": []string{"a"}}, to)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTransmuter_Cycle(t *testing.T) {
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[*cyclicNode, *cyclicNode](&Transmutation{
+		Encoding: Encoding{Cycles: CycleRef},
+	})
+	a.NoError(err)
+
+	to, err := tm.Run(cyclicList())
+	a.NoError(err)
+	if a.NotNil(to.Next) {
+		a.Same(to, to.Next.Prev)
+	}
+}
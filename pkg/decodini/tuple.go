@@ -0,0 +1,159 @@
+package decodini
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Tuple is implemented by struct types that are encoded and decoded as
+// tuples, i.e. as slices of their field values. The method is a marker and
+// never called.
+//
+// A struct field can be put into tuple mode by the tuple tag option as well,
+// e.g. `decodini:"user,tuple"`. The fields of a tuple are positioned in
+// declaration order, or explicitly using the index tag option, e.g.
+// `decodini:"name,index=2"`. Fields without index option are positioned after
+// the preceding field. Decoding into a tuple with an index option that is no
+// non-negative integer fails with ErrInvalidOption.
+type Tuple interface {
+	DecodiniTuple()
+}
+
+var tupleType = reflect.TypeFor[Tuple]()
+
+// isTupleType reports whether the struct type typ implements Tuple.
+func isTupleType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct &&
+		(typ.Implements(tupleType) || reflect.PointerTo(typ).Implements(tupleType))
+}
+
+// hasTuple reports whether typ is a struct type that is in tuple mode or has
+// fields in tuple mode.
+func hasTuple(tag string, typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	return isTupleType(typ) || structPlanOf(tag, typ).hasOption("tuple")
+}
+
+// tuplePositions returns the position in flat of the field at every tuple
+// position, or -1 for positions without field. If multiple fields have the
+// same position, the first one wins. It also returns the first field whose
+// index option is no non-negative integer, or nil. Such fields are positioned
+// as if they had no index option.
+func tuplePositions(flat []fieldPlan) ([]int, *fieldPlan) {
+	var (
+		positions []int
+		malformed *fieldPlan
+	)
+	next := 0
+	for i, field := range flat {
+		pos := next
+		if raw, ok := field.opts.Get("index"); ok {
+			index, err := strconv.Atoi(raw)
+			switch {
+			case err == nil && index >= 0:
+				pos = index
+			case malformed == nil:
+				malformed = &flat[i]
+			}
+		}
+		next = pos + 1
+
+		for len(positions) <= pos {
+			positions = append(positions, -1)
+		}
+		if positions[pos] < 0 {
+			positions[pos] = i
+		}
+	}
+	return positions, malformed
+}
+
+// isTupleTarget reports whether target is a struct in tuple mode.
+func (dec *Decoding) isTupleTarget(target DecodeTarget) bool {
+	return target.Value.Kind() == reflect.Struct &&
+		(isTupleType(target.Value.Type()) ||
+			target.field != nil && target.field.opts.Has("tuple"))
+}
+
+// intoStructFromTuple decodes the slice-like node into the struct target in
+// tuple mode, filling the fields by their position.
+func (dec *Decoding) intoStructFromTuple(node *Tree, target DecodeTarget) error {
+	plan := structPlanOf(dec.StructTag, target.Value.Type())
+	if field := plan.malformedIndex; field != nil {
+		raw, _ := field.option("index")
+		return newDecodeErrorf(
+			ErrInvalidOption,
+			node,
+			target,
+			"invalid index %q of struct field %s", raw, field.name,
+		)
+	}
+	for pos, i := range plan.tuple {
+		if i < 0 {
+			continue
+		}
+		field := &plan.flat[i]
+
+		from := node.Child(pos)
+		sub := target.sub(pos, settableField(target.Value, field.index))
+		sub.field = field
+
+		if from == nil {
+			if dec.Unmatched == nil {
+				return newDecodeErrorf(
+					ErrUnmatchedField,
+					node.dummyChild(pos),
+					target,
+					"tuple element %d of struct field %s is missing in source tree",
+					pos, field.name,
+				)
+			}
+			uFrom, uErr := dec.Unmatched(from, sub)
+			if uErr != nil {
+				return uErr
+			}
+			if uFrom == nil {
+				continue
+			}
+			from = uFrom
+		}
+
+		if err := dec.into(from, sub); err != nil {
+			return err
+		}
+	}
+
+	if !dec.Strict {
+		return nil
+	}
+	for child := range node.Children() {
+		pos := child.Name().(int)
+		if pos < len(plan.tuple) && plan.tuple[pos] >= 0 {
+			continue
+		}
+		return newDecodeErrorf(
+			ErrUnknownField,
+			child,
+			target,
+			"tuple element %d does not match any struct field", pos,
+		)
+	}
+	return nil
+}
+
+// settableField returns the nested field of the struct val at the given index
+// path, allocating nil embedded pointers on the way.
+func settableField(val reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && val.Kind() == reflect.Pointer {
+			if val.IsNil() {
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(x)
+	}
+	return val
+}
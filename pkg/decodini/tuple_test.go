@@ -0,0 +1,173 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tupleUser struct {
+	Name  string
+	Age   int
+	Admin bool
+}
+
+func (tupleUser) DecodiniTuple() {}
+
+func TestDecode_Slice_to_TupleStruct(t *testing.T) {
+	a := assert.New(t)
+
+	to, err := Decode[tupleUser](nil, Encode(nil, []any{"alice", 30, true}))
+	a.NoError(err)
+	a.Equal(tupleUser{Name: "alice", Age: 30, Admin: true}, to)
+
+	_, err = Decode[tupleUser](nil, Encode(nil, []any{"alice", "30", true}))
+	a.ErrorIs(err, ErrTypeMismatch)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Index(1)}, decErr.Path())
+	}
+
+	_, err = Decode[tupleUser](nil, Encode(nil, []any{"alice", 30}))
+	a.ErrorIs(err, ErrUnmatchedField)
+	a.EqualError(
+		err,
+		"decodini: decode: failed at 2: tuple element 2 of struct field Admin is missing in source tree",
+	)
+
+	_, err = Decode[tupleUser](nil, Encode(nil, map[string]any{"Name": "alice"}))
+	a.ErrorIs(err, ErrTypeMismatch)
+}
+
+func TestDecode_Slice_to_TupleStruct_Strict(t *testing.T) {
+	a := assert.New(t)
+
+	from := []any{"alice", 30, true, "extra"}
+
+	_, err := Decode[tupleUser](nil, Encode(nil, from))
+	a.NoError(err)
+
+	_, err = Decode[tupleUser](&Decoding{Strict: true}, Encode(nil, from))
+	a.ErrorIs(err, ErrUnknownField)
+
+	var decErr *DecodeError
+	if a.ErrorAs(err, &decErr) {
+		a.Equal(Path{Index(3)}, decErr.Path())
+	}
+}
+
+func TestDecode_TupleTag(t *testing.T) {
+	type point struct {
+		X int `decodini:"x,index=1"`
+		Y int `decodini:"y,index=0"`
+	}
+	type shape struct {
+		Origin point `decodini:"origin,tuple"`
+		Name   string
+	}
+
+	a := assert.New(t)
+
+	from := map[string]any{"origin": []int{2, 1}, "Name": "dot"}
+	to, err := Decode[shape](nil, Encode(nil, from))
+	a.NoError(err)
+	a.Equal(shape{Origin: point{X: 1, Y: 2}, Name: "dot"}, to)
+
+	back, err := Transmute[map[string]any](nil, to)
+	a.NoError(err)
+	a.Equal(map[string]any{"origin": []any{2, 1}, "Name": "dot"}, back)
+}
+
+func TestDecode_TupleTag_MalformedIndex(t *testing.T) {
+	type point struct {
+		X int `decodini:"x,index=-1"`
+		Y int `decodini:"y,index=one"`
+	}
+	type shape struct {
+		Origin point `decodini:"origin,tuple"`
+	}
+
+	a := assert.New(t)
+
+	_, err := Decode[shape](nil, Encode(nil, map[string]any{"origin": []int{1, 2}}))
+	a.ErrorIs(err, ErrInvalidOption)
+	a.EqualError(
+		err,
+		`decodini: decode: failed at origin: invalid index "-1" of struct field x`,
+	)
+}
+
+func TestEncode_Tuple(t *testing.T) {
+	type sparse struct {
+		A string
+		B string `decodini:"b,index=3"`
+	}
+	type holder struct {
+		V sparse `decodini:"v,tuple"`
+	}
+
+	a := assert.New(t)
+
+	tr := Encode(nil, tupleUser{Name: "alice", Age: 30, Admin: true})
+	a.True(tr.IsTuple())
+	a.Equal(uint(3), tr.NumChildren())
+
+	var names []any
+	var paths []Path
+	for child := range tr.Children() {
+		names = append(names, child.Value().Interface())
+		paths = append(paths, child.Path())
+	}
+	a.Equal([]any{"alice", 30, true}, names)
+	a.Equal([]Path{{Index(0)}, {Index(1)}, {Index(2)}}, paths)
+	a.Nil(tr.Child("Name"))
+
+	v := Encode(nil, holder{V: sparse{A: "a", B: "b"}}).Child("v")
+	a.True(v.IsTuple())
+	a.Equal(uint(4), v.NumChildren())
+	a.True(v.Child(1).IsNil())
+	a.Equal("b", v.Child(3).Value().Interface())
+
+	to, err := Decode[[]string](nil, v)
+	a.NoError(err)
+	a.Equal([]string{"a", "", "", "b"}, to)
+}
+
+type tupleRecord struct {
+	Tags []string `decodini:"tags,sep=','"`
+	ID   []byte   `decodini:"id,bytes=hex"`
+}
+
+func (tupleRecord) DecodiniTuple() {}
+
+func TestTransmute_Tuple_FieldOptions(t *testing.T) {
+	a := assert.New(t)
+
+	from := tupleRecord{Tags: []string{"a", "b"}, ID: []byte{0xca, 0xfe}}
+	to, err := Transmute[[]any](nil, from)
+	a.NoError(err)
+	a.Equal([]any{"a,b", "cafe"}, to)
+
+	back, err := Transmute[tupleRecord](nil, to)
+	a.NoError(err)
+	a.Equal(from, back)
+}
+
+func TestTransmuter_Tuple(t *testing.T) {
+	a := assert.New(t)
+
+	tm, err := NewTransmuter[tupleUser, []any](nil)
+	a.NoError(err)
+
+	to, err := tm.Run(tupleUser{Name: "alice", Age: 30})
+	a.NoError(err)
+	a.Equal([]any{"alice", 30, false}, to)
+
+	back, err := NewTransmuter[[]any, tupleUser](nil)
+	a.NoError(err)
+
+	user, err := back.Run(to)
+	a.NoError(err)
+	a.Equal(tupleUser{Name: "alice", Age: 30}, user)
+}
@@ -0,0 +1,73 @@
+package decodini
+
+import "fmt"
+
+// WarningKind classifies a Warning.
+type WarningKind string
+
+const (
+	// WarningDeprecated is reported when a source key matches a struct field
+	// that is marked as deprecated using the `deprecated` tag option.
+	WarningDeprecated WarningKind = "deprecated"
+
+	// WarningLossyConversion is reported when a numeric conversion does not
+	// preserve the source value, e.g. when a float is truncated to an integer.
+	WarningLossyConversion WarningKind = "lossy conversion"
+)
+
+// Warning describes a situation that does not fail decoding, but should be
+// surfaced to the user.
+type Warning struct {
+	Path    Path
+	Kind    WarningKind
+	Message string
+}
+
+// String returns a human-readable representation of the warning.
+func (w Warning) String() string {
+	path := w.Path.String()
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Sprintf("decodini: %s at %s: %s", w.Kind, path, w.Message)
+}
+
+// warn reports a warning for node if a Warn callback is configured.
+func (dec *Decoding) warn(
+	target DecodeTarget,
+	node *Tree,
+	kind WarningKind,
+	format string,
+	args ...any,
+) {
+	if dec.Warn == nil {
+		return
+	}
+	dec.report(target, Warning{
+		Path:    node.Path(),
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// report passes w to the Warn callback, or buffers it if target is decoded
+// concurrently with its siblings.
+func (dec *Decoding) report(target DecodeTarget, w Warning) {
+	if target.state != nil && target.state.forked != nil {
+		target.state.forked.warnings = append(target.state.forked.warnings, w)
+		return
+	}
+	dec.Warn(w)
+}
+
+func (dec *Decoding) warnDeprecated(
+	target DecodeTarget,
+	node *Tree,
+	name, msg string,
+) {
+	if msg == "" {
+		dec.warn(target, node, WarningDeprecated, "%s is deprecated", name)
+		return
+	}
+	dec.warn(target, node, WarningDeprecated, "%s is deprecated: %s", name, msg)
+}
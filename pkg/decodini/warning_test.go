@@ -0,0 +1,68 @@
+package decodini
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode_Warn_Deprecated(t *testing.T) {
+	type toStruct struct {
+		Timeout     int `decodini:"timeout"`
+		TimeoutSecs int `decodini:"timeout_secs,deprecated='use timeout instead'"`
+	}
+
+	a := assert.New(t)
+
+	var warnings []Warning
+	dec := &Decoding{
+		Warn: func(w Warning) { warnings = append(warnings, w) },
+		Unmatched: func(*Tree, DecodeTarget) (*Tree, error) {
+			return nil, nil
+		},
+	}
+
+	_, err := Decode[toStruct](dec, Encode(nil, map[string]any{"timeout": 1}))
+	a.NoError(err)
+	a.Empty(warnings)
+
+	_, err = Decode[toStruct](dec, Encode(nil, map[string]any{"timeout_secs": 1}))
+	a.NoError(err)
+	a.Equal([]Warning{{
+		Path:    Path{Key{Value: "timeout_secs"}},
+		Kind:    WarningDeprecated,
+		Message: "timeout_secs is deprecated: use timeout instead",
+	}}, warnings)
+	a.Equal(
+		"decodini: deprecated at timeout_secs: timeout_secs is deprecated: use timeout instead",
+		warnings[0].String(),
+	)
+}
+
+func TestDecode_Warn_LossyConversion(t *testing.T) {
+	a := assert.New(t)
+
+	var warnings []Warning
+	dec := &Decoding{
+		Warn: func(w Warning) { warnings = append(warnings, w) },
+	}
+
+	to, err := Decode[[]int](dec, Encode(nil, []float64{1, 2.5}))
+	a.NoError(err)
+	a.Equal([]int{1, 2}, to)
+
+	if a.Len(warnings, 1) {
+		a.Equal(WarningLossyConversion, warnings[0].Kind)
+		a.Equal(Path{Index(1)}, warnings[0].Path)
+	}
+
+	warnings = nil
+	_, err = Decode[float32](dec, Encode(nil, 0.1))
+	a.NoError(err)
+	a.Len(warnings, 1)
+
+	warnings = nil
+	_, err = Decode[float64](dec, Encode(nil, float32(0.1)))
+	a.NoError(err)
+	a.Empty(warnings)
+}